@@ -0,0 +1,127 @@
+// Команда importer загружает фильмы из Kinopoisk в каталог:
+//
+//	importer                                  популярные фильмы (TOP_POPULAR_ALL)
+//	importer -collection TOP_250,TOP_POPULAR_ALL
+//	importer -keyword "матрица"
+//	importer -ids films.txt                   Kinopoisk ID по одному в строке
+//
+// Прогресс сохраняется в БД: прерванный импорт продолжается с места остановки,
+// неудачные страницы повторяются, а завершённый источник повторно не
+// загружается (для этого есть -restart). -dry-run показывает, сколько осталось
+// загрузить, ничего не записывая.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/config"
+	"github.com/AlexKeyyyy/movies-picker/internal/importer"
+	"github.com/AlexKeyyyy/movies-picker/internal/repository"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// collectionAliases — короткие имена подборок Kinopoisk
+var collectionAliases = map[string]string{
+	"TOP_250": kinopoisk.CollectionTop250,
+}
+
+func main() {
+	collections := flag.String("collection", "", "comma-separated Kinopoisk collections (TOP_POPULAR_ALL, TOP_250, …)")
+	keyword := flag.String("keyword", "", "import Kinopoisk search results for a keyword")
+	idsFile := flag.String("ids", "", "file with Kinopoisk film IDs, one per line")
+	workers := flag.Int("workers", 4, "pages or films loaded concurrently")
+	retries := flag.Int("retries", 3, "retries of a failed page within one run")
+	backoff := flag.Duration("backoff", 2*time.Second, "pause before the first retry, doubled after each")
+	dryRun := flag.Bool("dry-run", false, "report what is left to import without writing to the DB")
+	restart := flag.Bool("restart", false, "forget saved progress and import the sources again")
+	people := flag.Bool("people", false, "also import cast and crew of every film (one more request per film)")
+	flag.Parse()
+
+	cfg := config.Load()
+	if cfg.DBUrl == "" {
+		log.Fatal("DB_URL is not set")
+	}
+	if cfg.KinopoiskApiKey == "" {
+		log.Fatal("KINOPOISK_API_KEY is not set")
+	}
+	repo, err := repository.NewRepo(cfg.DBUrl, cfg.DBQueryTimeout)
+	if err != nil {
+		log.Fatalf("failed to connect to DB: %v", err)
+	}
+	kp := kinopoisk.NewClientWithOptions(cfg.KinopoiskApiKey, cfg.KinopoiskOptions())
+
+	var sources []importer.Source
+	for _, c := range strings.Split(*collections, ",") {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c == "" {
+			continue
+		}
+		if alias, ok := collectionAliases[c]; ok {
+			c = alias
+		}
+		sources = append(sources, importer.Collection(kp, c))
+	}
+	if *keyword != "" {
+		sources = append(sources, importer.Keyword(kp, *keyword))
+	}
+	if *idsFile != "" {
+		f, err := os.Open(*idsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ids, err := importer.ReadIDs(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *idsFile, err)
+		}
+		sources = append(sources, importer.IDs(kp, filepath.Base(*idsFile), ids))
+	}
+	if len(sources) == 0 {
+		sources = append(sources, importer.Collection(kp, kinopoisk.CollectionPopularAll))
+	}
+
+	// Ctrl+C: дожидаемся начатых страниц, прогресс остаётся в чекпоинте
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := importer.Options{
+		Workers: *workers,
+		Retries: *retries,
+		Backoff: *backoff,
+		DryRun:  *dryRun,
+		Restart: *restart,
+	}
+	if *people {
+		opts.Staff = kp
+	}
+	im := importer.New(repo, opts)
+	ok := true
+	for _, src := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+		rep, err := im.Run(ctx, src)
+		if err != nil {
+			log.Printf("%s: %v", src.Name(), err)
+			ok = false
+			continue
+		}
+		rep.Print(os.Stdout)
+		ok = ok && len(rep.Failed) == 0 && !rep.Interrupted && rep.Stopped == ""
+		if rep.Stopped != "" {
+			break // остальные источники упрутся в ту же ошибку
+		}
+	}
+	fmt.Printf("Kinopoisk requests: %d\n", kp.RequestsToday())
+	if !ok {
+		os.Exit(1)
+	}
+}
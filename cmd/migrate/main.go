@@ -0,0 +1,98 @@
+// Команда migrate управляет схемой БД:
+//
+//	migrate up                  применить все новые миграции
+//	migrate down [-steps N]     откатить N последних миграций (по умолчанию 1)
+//	migrate status              показать применённые и ожидающие миграции
+//	migrate new [-dir D] NAME   создать пустую пару файлов миграции
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/migrate"
+	"github.com/joho/godotenv"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up | down [-steps N] | status | new [-dir DIR] NAME")
+	os.Exit(2)
+}
+
+func main() {
+	_ = godotenv.Load()
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+
+	if cmd == "new" {
+		fs := flag.NewFlagSet("new", flag.ExitOnError)
+		dir := fs.String("dir", "db/migrations", "directory with migration files")
+		fs.Parse(args)
+		if fs.NArg() != 1 {
+			usage()
+		}
+		up, down, err := migrate.Create(*dir, fs.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return
+	}
+
+	dsn := os.Getenv("DB_URL")
+	if dsn == "" {
+		log.Fatal("DB_URL is not set")
+	}
+	m, err := migrate.Open(dsn)
+	if err != nil {
+		log.Fatalf("failed to connect to DB: %v", err)
+	}
+	defer m.Close()
+
+	switch cmd {
+	case "up":
+		applied, err := m.Up()
+		for _, mig := range applied {
+			fmt.Printf("applied  %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		fs.Parse(args)
+		if *steps < 1 {
+			log.Fatal("steps must be positive")
+		}
+		reverted, err := m.Down(*steps)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		list, err := m.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, st := range list {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", st.Version, st.Name, state)
+		}
+	default:
+		usage()
+	}
+}
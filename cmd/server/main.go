@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
@@ -9,9 +10,13 @@ import (
 	"github.com/AlexKeyyyy/movies-picker/config"
 	"github.com/AlexKeyyyy/movies-picker/internal/handlers"
 	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/AlexKeyyyy/movies-picker/internal/migrate"
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/problem"
 	"github.com/AlexKeyyyy/movies-picker/internal/repository"
 	"github.com/AlexKeyyyy/movies-picker/internal/service"
 	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/mailer"
 	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
 	"github.com/go-chi/chi/v5"
     "github.com/go-chi/cors"
@@ -19,57 +24,145 @@ import (
 
 func main() {
 	cfg := config.Load()
-	repo, err := repository.NewRepo(cfg.DBUrl)
+	if cfg.AutoMigrate {
+		if err := runMigrations(cfg.DBUrl); err != nil {
+			log.Fatal(err)
+		}
+	}
+	repo, err := repository.NewRepo(cfg.DBUrl, cfg.DBQueryTimeout)
 	if err != nil {
 		log.Fatal(err)
 	}
-	kpClient := kinopoisk.NewClient(cfg.KinopoiskApiKey)
-	ytClient := youtube.NewClient(cfg.YouTubeApiKey)
-	svc := service.NewService(repo, kpClient, ytClient, cfg.JWTSecret)
+	kpClient := kinopoisk.NewClientWithOptions(cfg.KinopoiskApiKey, cfg.KinopoiskOptions())
+	ytClient := youtube.NewClientWithOptions(cfg.YouTubeApiKey, cfg.YouTubeOptions())
+	var mail mailer.Mailer = mailer.NewFile(cfg.MailDir, cfg.MailFrom)
+	if cfg.SMTPHost != "" {
+		mail = mailer.NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.MailFrom)
+	}
+	svc := service.NewService(repo, kpClient, ytClient, mail, cfg.JWTSecret, cfg.AppURL)
+	if err := svc.EnsureAdmins(context.Background(), cfg.AdminEmails); err != nil {
+		log.Fatal(err)
+	}
+	svc.SetVideosTTL(cfg.ReviewsTTL, cfg.TrailersTTL)
+	svc.SetReviewProviderTimeout(cfg.ReviewProviderTimeout)
+	svc.StartCatalogSync(context.Background(), service.SyncConfig{
+		Interval:    cfg.SyncInterval,
+		TTL:         cfg.SyncTTL,
+		BatchSize:   cfg.SyncBatchSize,
+		DailyBudget: cfg.KinopoiskDailyBudget,
+	})
 
 	authH := handlers.NewAuthHandler(svc)
 	userH := handlers.NewUserHandler(svc)
 	moviesH := handlers.NewMoviesHandler(svc)
 	watchH := handlers.NewWatchlistHandler(svc)
 	rateH := handlers.NewRatingsHandler(svc)
+	recH := handlers.NewRecommendationsHandler(svc)
+	pickH := handlers.NewPickerHandler(svc)
+	sessH := handlers.NewSessionsHandler(svc)
+	adminH := handlers.NewAdminHandler(svc)
+	peopleH := handlers.NewPeopleHandler(svc)
+	reviewsH := handlers.NewReviewsHandler(svc)
 
 	r := chi.NewRouter()
 
 	r.Use(cors.Handler(cors.Options{
         AllowedOrigins:   []string{"http://localhost:5173"},
-        AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+        AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
         AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
         AllowCredentials: true,
         MaxAge:           300,
     }))
+	r.Use(middleware.Timeout(cfg.RequestTimeout))
+	r.NotFound(problem.NotFound)
+	r.MethodNotAllowed(problem.MethodNotAllowed)
 
 	// --- Public endpoints ---
 	r.Post("/auth/register", authH.Register)
 	r.Post("/auth/login", authH.Login)
-
-	r.Get("/movies", moviesH.ListMovies)                   // список фильмов с пагинацией
-	r.Get("/movies/search", moviesH.SearchMovies)          // поиск
-	r.Get("/movies/{id}", moviesH.GetMovie)                // детали
-	r.Get("/movies/{id}/reviews", moviesH.GetMovieReviews) // обзоры
-	r.Get("/movies/popular", moviesH.ListPopular)          // топ-N популярных
+	r.Post("/auth/refresh", authH.Refresh)
+	r.Post("/auth/verify", authH.VerifyEmail)
+	r.Post("/auth/password/forgot", authH.ForgotPassword)
+	r.Post("/auth/password/reset", authH.ResetPassword)
+
+	r.Get("/movies", moviesH.ListMovies)                         // список фильмов с пагинацией
+	r.Get("/movies/search", moviesH.SearchMovies)                // поиск
+	r.Get("/movies/{id}", moviesH.GetMovie)                      // детали
+	r.Get("/movies/{id}/reviews", moviesH.GetMovieReviews)       // рецензии из всех источников
+	r.Get("/movies/{id}/videos", moviesH.GetMovieVideos)         // трейлеры, тизеры и обзоры
+	r.Get("/movies/popular", moviesH.ListPopular)                // топ-N популярных
+	r.Get("/movies/{id}/cast", moviesH.GetMovieCast)             // актёры и съёмочная группа
+	r.Get("/movies/{id}/user-reviews", reviewsH.ListUserReviews) // рецензии пользователей
+	r.Get("/people/search", peopleH.SearchPeople)                // поиск людей по имени
+	r.Get("/people/{id}", peopleH.GetPerson)                     // человек и его фильмы
 
 	// --- Protected endpoints (JWT required) ---
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.JWT(cfg.JWTSecret))
+		r.Use(middleware.JWT(cfg.JWTSecret, svc))
+
+		r.Post("/auth/logout", authH.Logout)
+		r.Post("/auth/logout-all", authH.LogoutAll)
 
 		// профиль
 		r.Get("/users/me", userH.GetProfile)
 		r.Patch("/users/me", userH.UpdateProfile)
 
-		// «Смотреть позже»
-		r.Get("/users/{userID}/watchlist", watchH.GetWatchlist)
-		r.Post("/users/{userID}/watchlist", watchH.AddToWatchlist)
-		r.Delete("/users/{userID}/watchlist/{movieID}", watchH.RemoveFromWatchlist)
-
-		// рейтинги
-		r.Get("/users/{userID}/ratings", rateH.GetRatings)
-		r.Post("/users/{userID}/ratings", rateH.AddOrUpdateRating)
-		r.Delete("/users/{userID}/ratings/{movieID}", rateH.DeleteRating)
+		// персональные рекомендации
+		r.Get("/users/me/recommendations", recH.GetRecommendations)
+		r.Get("/users/me/pick", pickH.PickMovie) // фильм на вечер
+
+		// данные конкретного пользователя: {userID} — свой ID или «me»;
+		// администратор может работать с данными любого пользователя
+		r.Route("/users/{userID}", func(r chi.Router) {
+			r.Use(middleware.PathUser("userID", middleware.RoleOverride(models.RoleAdmin)))
+
+			// «Смотреть позже»
+			r.Get("/watchlist", watchH.GetWatchlist)
+			r.Post("/watchlist", watchH.AddToWatchlist)
+			r.Delete("/watchlist/{movieID}", watchH.RemoveFromWatchlist)
+
+			// рейтинги
+			r.Get("/ratings", rateH.GetRatings)
+			r.Post("/ratings", rateH.AddOrUpdateRating)
+			r.Delete("/ratings/{movieID}", rateH.DeleteRating)
+		})
+
+		// своя рецензия к оценке фильма; голоса и жалобы на чужие
+		r.Get("/movies/{id}/my-review", reviewsH.GetMyReview)
+		r.Post("/movies/{id}/my-review", reviewsH.CreateMyReview)
+		r.Put("/movies/{id}/my-review", reviewsH.UpdateMyReview)
+		r.Delete("/movies/{id}/my-review", reviewsH.DeleteMyReview)
+		r.Put("/movies/{id}/user-reviews/{userID}/vote", reviewsH.VoteReview)
+		r.Delete("/movies/{id}/user-reviews/{userID}/vote", reviewsH.DeleteReviewVote)
+		r.Post("/movies/{id}/user-reviews/{userID}/report", reviewsH.ReportReview)
+
+		// совместный выбор фильма
+		r.Post("/sessions", sessH.CreateSession)
+		r.Get("/sessions/{sessionID}", sessH.GetSession)
+		r.Post("/sessions/{sessionID}/join", sessH.JoinSession)
+		r.Post("/sessions/{sessionID}/votes", sessH.Vote)
+		r.Get("/sessions/{sessionID}/result", sessH.GetResult)
+
+		// --- Admin: модераторы курируют каталог, администраторы — ещё и пользователей ---
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middleware.RequireRole(models.RoleModerator, models.RoleAdmin))
+
+			r.Patch("/movies/{id}", adminH.UpdateMovie)
+			r.Delete("/movies/{id}", adminH.DeleteMovie)
+			r.Post("/movies/{id}/merge", adminH.MergeMovie)
+			r.Post("/movies/{id}/sync", adminH.ResyncMovie)
+			r.Get("/reviews", adminH.ListReviewQueue)
+			r.Post("/movies/{id}/reviews/{userID}/hide", adminH.HideReview)
+			r.Post("/movies/{id}/reviews/{userID}/restore", adminH.RestoreReview)
+			r.Get("/sync", adminH.SyncStatus)
+			r.Post("/sync", adminH.TriggerSync)
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireRole(models.RoleAdmin))
+				r.Get("/users", adminH.ListUsers)
+				r.Patch("/users/{userID}", adminH.UpdateUser)
+			})
+		})
 	})
 
 	// --- OpenAPI спецификация ---
@@ -86,3 +179,17 @@ func main() {
 	log.Fatal(http.ListenAndServe(":"+cfg.Port, r))
 
 }
+
+// runMigrations приводит схему БД к последней версии
+func runMigrations(dbURL string) error {
+	m, err := migrate.Open(dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	applied, err := m.Up()
+	for _, mig := range applied {
+		log.Printf("migration applied: %04d_%s", mig.Version, mig.Name)
+	}
+	return err
+}
@@ -3,7 +3,12 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
 	"github.com/joho/godotenv"
 )
 
@@ -13,6 +18,48 @@ type Config struct {
 	JWTSecret       string
 	KinopoiskApiKey string
 	YouTubeApiKey   string
+	AutoMigrate     bool     // применять миграции БД при старте сервера
+	AdminEmails     []string // пользователи, получающие роль admin при старте
+	AppURL          string   // адрес фронтенда для ссылок в письмах
+
+	// почта: при пустом SMTPHost письма пишутся в MailDir (или в лог)
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	MailFrom     string
+	MailDir      string
+
+	// фоновое обновление каталога из Kinopoisk
+	SyncInterval         time.Duration // 0 — выключено
+	SyncTTL              time.Duration
+	SyncBatchSize        int
+	KinopoiskDailyBudget int // запросов к Kinopoisk в сутки, после которых обновление ждёт; 0 — без ограничения
+
+	// клиент Kinopoisk
+	KinopoiskRateLimit  int // запросов в секунду
+	KinopoiskRetries    int // повторов при сетевых ошибках, 5xx и 429
+	KinopoiskDailyQuota int // суточная квота ключа; 0 — узнаём о ней по ответу 402
+
+	// видео фильмов хранятся в БД и обновляются в фоне
+	ReviewsTTL  time.Duration
+	TrailersTTL time.Duration
+
+	// сколько ждать каждый источник рецензий (YouTube, Kinopoisk, пользователи)
+	ReviewProviderTimeout time.Duration
+
+	// поиск обзоров на YouTube; пустые значения не передаются в API
+	YouTubeLanguage        string   // relevanceLanguage
+	YouTubeRegion          string   // regionCode
+	YouTubeVideoDuration   string   // any, short, medium, long
+	YouTubeOrder           string   // relevance, viewCount, date, rating
+	YouTubeTrustedChannels []string // ID или названия каналов, которым доверяем
+
+	// дедлайны операций; 0 — без ограничения
+	RequestTimeout   time.Duration // обработка одного HTTP-запроса целиком
+	DBQueryTimeout   time.Duration // одна операция с БД
+	KinopoiskTimeout time.Duration // один вызов Kinopoisk вместе с повторами
+	YouTubeTimeout   time.Duration // один поиск на YouTube
 }
 
 func Load() *Config {
@@ -25,5 +72,110 @@ func Load() *Config {
 		JWTSecret:       os.Getenv("JWT_SECRET"),
 		KinopoiskApiKey: os.Getenv("KINOPOISK_API_KEY"),
 		YouTubeApiKey:   os.Getenv("YOUTUBE_API_KEY"),
+		AutoMigrate:     os.Getenv("AUTO_MIGRATE") != "false",
+		AdminEmails:     splitList(os.Getenv("ADMIN_EMAILS")),
+		AppURL:          getenv("APP_URL", "http://localhost:5173"),
+		SMTPHost:        os.Getenv("SMTP_HOST"),
+		SMTPPort:        getenv("SMTP_PORT", "587"),
+		SMTPUser:        os.Getenv("SMTP_USER"),
+		SMTPPassword:    os.Getenv("SMTP_PASSWORD"),
+		MailFrom:        getenv("MAIL_FROM", "noreply@movies-picker.local"),
+		MailDir:         os.Getenv("MAIL_DIR"),
+
+		SyncInterval:         getDuration("SYNC_INTERVAL", time.Hour),
+		SyncTTL:              getDuration("SYNC_TTL", 7*24*time.Hour),
+		SyncBatchSize:        getInt("SYNC_BATCH_SIZE", 100),
+		KinopoiskDailyBudget: getInt("KINOPOISK_DAILY_BUDGET", 400),
+
+		KinopoiskRateLimit:  getInt("KINOPOISK_RATE_LIMIT", 10),
+		KinopoiskRetries:    getInt("KINOPOISK_RETRIES", 3),
+		KinopoiskDailyQuota: getInt("KINOPOISK_DAILY_QUOTA", 0),
+
+		ReviewsTTL:  getDuration("REVIEWS_TTL", 72*time.Hour),
+		TrailersTTL: getDuration("TRAILERS_TTL", 7*24*time.Hour),
+
+		ReviewProviderTimeout: getDuration("REVIEW_PROVIDER_TIMEOUT", 5*time.Second),
+
+		YouTubeLanguage:        getenv("YOUTUBE_LANGUAGE", "ru"),
+		YouTubeRegion:          getenv("YOUTUBE_REGION", "RU"),
+		YouTubeVideoDuration:   os.Getenv("YOUTUBE_VIDEO_DURATION"),
+		YouTubeOrder:           getenv("YOUTUBE_ORDER", "relevance"),
+		YouTubeTrustedChannels: splitList(os.Getenv("YOUTUBE_TRUSTED_CHANNELS")),
+
+		RequestTimeout:   getDuration("REQUEST_TIMEOUT", 30*time.Second),
+		DBQueryTimeout:   getDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+		KinopoiskTimeout: getDuration("KINOPOISK_TIMEOUT", 20*time.Second),
+		YouTubeTimeout:   getDuration("YOUTUBE_TIMEOUT", 10*time.Second),
+	}
+}
+
+// splitList разбирает список через запятую, пропуская пустые элементы
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// getenv возвращает переменную окружения или значение по умолчанию
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getDuration читает длительность вида 30m или 24h
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("invalid %s=%q, using %s", key, v, def)
+		return def
+	}
+	return d
+}
+
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("invalid %s=%q, using %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// KinopoiskOptions — настройки клиента Kinopoisk из окружения
+func (c *Config) KinopoiskOptions() kinopoisk.Options {
+	opts := kinopoisk.DefaultOptions
+	opts.RateLimit = float64(c.KinopoiskRateLimit)
+	opts.Burst = c.KinopoiskRateLimit
+	opts.Retry.MaxAttempts = c.KinopoiskRetries + 1
+	opts.DailyQuota = c.KinopoiskDailyQuota
+	opts.OpTimeout = c.KinopoiskTimeout
+	return opts
+}
+
+// YouTubeOptions — настройки клиента YouTube из окружения
+func (c *Config) YouTubeOptions() youtube.Options {
+	opts := youtube.DefaultOptions
+	opts.OpTimeout = c.YouTubeTimeout
+	opts.Search = youtube.SearchOptions{
+		Language:        c.YouTubeLanguage,
+		Region:          c.YouTubeRegion,
+		VideoDuration:   c.YouTubeVideoDuration,
+		Order:           c.YouTubeOrder,
+		TrustedChannels: c.YouTubeTrustedChannels,
 	}
+	return opts
 }
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS watchlist;
+DROP TABLE IF EXISTS movies;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS session_votes;
+DROP TABLE IF EXISTS session_members;
+DROP TABLE IF EXISTS movie_sessions;
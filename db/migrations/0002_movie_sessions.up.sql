@@ -0,0 +1,29 @@
+-- Совместный выбор фильма несколькими пользователями
+CREATE TABLE IF NOT EXISTS movie_sessions (
+  session_id SERIAL PRIMARY KEY,
+  owner_id   INT NOT NULL,
+  title      VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY(owner_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS session_members (
+  session_id INT NOT NULL,
+  user_id    INT NOT NULL,
+  status     VARCHAR(16) NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'joined')),
+  joined_at  TIMESTAMP,
+  PRIMARY KEY(session_id, user_id),
+  FOREIGN KEY(session_id) REFERENCES movie_sessions(session_id) ON DELETE CASCADE,
+  FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS session_votes (
+  session_id INT NOT NULL,
+  user_id    INT NOT NULL,
+  movie_id   BIGINT NOT NULL,
+  kind       VARCHAR(8) NOT NULL CHECK (kind IN ('up', 'down', 'veto')),
+  voted_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(session_id, user_id, movie_id),
+  FOREIGN KEY(session_id, user_id) REFERENCES session_members(session_id, user_id) ON DELETE CASCADE,
+  FOREIGN KEY(movie_id) REFERENCES movies(movie_id) ON DELETE CASCADE
+);
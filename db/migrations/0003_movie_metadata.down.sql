@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS movie_countries;
+DROP TABLE IF EXISTS countries;
+DROP TABLE IF EXISTS movie_genres;
+DROP TABLE IF EXISTS genres;
+
+ALTER TABLE movies
+  DROP COLUMN IF EXISTS title_en,
+  DROP COLUMN IF EXISTS original_title,
+  DROP COLUMN IF EXISTS runtime,
+  DROP COLUMN IF EXISTS age_rating,
+  DROP COLUMN IF EXISTS rating_imdb,
+  DROP COLUMN IF EXISTS film_type;
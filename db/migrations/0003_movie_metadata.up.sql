@@ -0,0 +1,33 @@
+ALTER TABLE movies
+  ADD COLUMN IF NOT EXISTS title_en       VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS original_title VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS runtime        INT NOT NULL DEFAULT 0,            -- минуты
+  ADD COLUMN IF NOT EXISTS age_rating     VARCHAR(16) NOT NULL DEFAULT '',   -- age0, age6, ..., age18
+  ADD COLUMN IF NOT EXISTS rating_imdb    NUMERIC(3,1) NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS film_type      VARCHAR(32) NOT NULL DEFAULT '';   -- FILM, TV_SERIES, MINI_SERIES, ...
+
+CREATE TABLE IF NOT EXISTS genres (
+  genre_id SERIAL PRIMARY KEY,
+  name     VARCHAR(64) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS movie_genres (
+  movie_id BIGINT NOT NULL,
+  genre_id INT NOT NULL,
+  PRIMARY KEY(movie_id, genre_id),
+  FOREIGN KEY(movie_id) REFERENCES movies(movie_id) ON DELETE CASCADE,
+  FOREIGN KEY(genre_id) REFERENCES genres(genre_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS countries (
+  country_id SERIAL PRIMARY KEY,
+  name       VARCHAR(64) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS movie_countries (
+  movie_id   BIGINT NOT NULL,
+  country_id INT NOT NULL,
+  PRIMARY KEY(movie_id, country_id),
+  FOREIGN KEY(movie_id) REFERENCES movies(movie_id) ON DELETE CASCADE,
+  FOREIGN KEY(country_id) REFERENCES countries(country_id) ON DELETE CASCADE
+);
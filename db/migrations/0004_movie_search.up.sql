@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- полнотекстовый индекс: русская и английская морфология по названиям и описанию
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+  setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+  setweight(to_tsvector('english', coalesce(title_en, '') || ' ' || coalesce(original_title, '')), 'A') ||
+  setweight(to_tsvector('russian', coalesce(description, '')), 'C') ||
+  setweight(to_tsvector('english', coalesce(description, '')), 'D')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS movies_search_vector_idx ON movies USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS movies_title_trgm_idx ON movies USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS movies_original_title_trgm_idx ON movies USING GIN (original_title gin_trgm_ops);
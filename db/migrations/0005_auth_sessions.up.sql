@@ -0,0 +1,26 @@
+-- Сессии входа: одна на каждый логин, к ней привязана цепочка refresh-токенов
+CREATE TABLE IF NOT EXISTS auth_sessions (
+  session_id VARCHAR(32) PRIMARY KEY,
+  user_id    INT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  revoked_at TIMESTAMP,
+  FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS auth_sessions_user_idx ON auth_sessions (user_id);
+
+-- Refresh-токены хранятся только в виде SHA-256; used_at выставляется при ротации
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  token_hash CHAR(64) PRIMARY KEY,
+  session_id VARCHAR(32) NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  used_at    TIMESTAMP,
+  FOREIGN KEY(session_id) REFERENCES auth_sessions(session_id) ON DELETE CASCADE
+);
+
+-- Отозванные access-токены (по jti) до истечения их срока действия
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+  jti        VARCHAR(32) PRIMARY KEY,
+  expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS role        VARCHAR(16) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin')),
+  ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
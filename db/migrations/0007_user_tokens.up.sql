@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Одноразовые токены из писем: подтверждение email и сброс пароля.
+-- email — адрес, который подтверждается (при смене email это новый адрес).
+CREATE TABLE IF NOT EXISTS user_tokens (
+  token_hash CHAR(64) PRIMARY KEY,
+  user_id    INT NOT NULL,
+  purpose    VARCHAR(16) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+  email      VARCHAR(255) NOT NULL DEFAULT '',
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  used_at    TIMESTAMP,
+  FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_idx ON user_tokens (user_id, purpose);
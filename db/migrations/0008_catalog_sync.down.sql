@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ratings_movie_idx;
+DROP INDEX IF EXISTS watchlist_movie_idx;
+DROP INDEX IF EXISTS movies_last_sync_idx;
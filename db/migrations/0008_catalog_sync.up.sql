@@ -0,0 +1,5 @@
+-- Фоновое обновление каталога выбирает фильмы по last_sync и приоритетно
+-- обновляет те, что есть в списках и оценках
+CREATE INDEX IF NOT EXISTS movies_last_sync_idx ON movies (last_sync);
+CREATE INDEX IF NOT EXISTS watchlist_movie_idx ON watchlist (movie_id);
+CREATE INDEX IF NOT EXISTS ratings_movie_idx ON ratings (movie_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS import_units;
+DROP TABLE IF EXISTS import_jobs;
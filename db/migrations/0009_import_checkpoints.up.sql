@@ -0,0 +1,21 @@
+-- Чекпоинты импорта каталога: повторный запуск импортёра продолжает с места
+-- остановки и не запрашивает уже загруженные страницы
+CREATE TABLE IF NOT EXISTS import_jobs (
+  source      TEXT PRIMARY KEY, -- например collection:TOP_250_MOVIES или keyword:матрица
+  total_pages INT NOT NULL DEFAULT 0,
+  started_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMP
+);
+
+-- Единица работы — страница подборки или ID фильма из списка
+CREATE TABLE IF NOT EXISTS import_units (
+  source     TEXT NOT NULL REFERENCES import_jobs(source) ON DELETE CASCADE,
+  unit       BIGINT NOT NULL,
+  done       BOOLEAN NOT NULL DEFAULT FALSE,
+  attempts   INT NOT NULL DEFAULT 0,
+  movies     INT NOT NULL DEFAULT 0,
+  error      TEXT,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (source, unit)
+);
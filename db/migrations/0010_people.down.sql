@@ -0,0 +1,3 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS people_synced_at;
+DROP TABLE IF EXISTS movie_people;
+DROP TABLE IF EXISTS people;
@@ -0,0 +1,27 @@
+-- Актёры и съёмочная группа; person_id — staffId в Kinopoisk
+CREATE TABLE IF NOT EXISTS people (
+  person_id  BIGINT PRIMARY KEY,
+  name       VARCHAR(255) NOT NULL,
+  name_en    VARCHAR(255) NOT NULL DEFAULT '',
+  photo_url  TEXT NOT NULL DEFAULT '',
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS people_name_trgm_idx ON people USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS people_name_en_trgm_idx ON people USING GIN (name_en gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS movie_people (
+  movie_id   BIGINT NOT NULL,
+  person_id  BIGINT NOT NULL,
+  profession VARCHAR(32) NOT NULL,           -- DIRECTOR, ACTOR, WRITER, ...
+  role       TEXT NOT NULL DEFAULT '',        -- персонаж у актёров
+  position   INT NOT NULL DEFAULT 0,         -- порядок в титрах Kinopoisk
+  PRIMARY KEY(movie_id, person_id, profession),
+  FOREIGN KEY(movie_id) REFERENCES movies(movie_id) ON DELETE CASCADE,
+  FOREIGN KEY(person_id) REFERENCES people(person_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS movie_people_person_idx ON movie_people (person_id);
+
+-- когда состав фильма последний раз загружался из Kinopoisk; NULL — ещё не загружался
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS people_synced_at TIMESTAMP;
@@ -0,0 +1,2 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS reviews_fetched_at;
+DROP TABLE IF EXISTS movie_reviews;
@@ -0,0 +1,17 @@
+-- Кэш видеообзоров с YouTube: поиск тратит 100 единиц суточной квоты,
+-- поэтому результаты хранятся и обновляются не чаще раза в REVIEWS_TTL
+CREATE TABLE IF NOT EXISTS movie_reviews (
+  movie_id      BIGINT NOT NULL REFERENCES movies(movie_id) ON DELETE CASCADE,
+  video_id      VARCHAR(32) NOT NULL,
+  video_url     TEXT NOT NULL,
+  title         TEXT NOT NULL DEFAULT '',
+  channel_title TEXT NOT NULL DEFAULT '',
+  thumbnail_url TEXT NOT NULL DEFAULT '',
+  position      INT NOT NULL DEFAULT 0, -- порядок в выдаче YouTube
+  fetched_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (movie_id, video_id)
+);
+
+-- когда обзоры фильма последний раз запрашивались; NULL — ещё не запрашивались.
+-- Отдельно от movie_reviews, чтобы не искать повторно фильмы без обзоров.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS reviews_fetched_at TIMESTAMP;
@@ -0,0 +1,9 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS trailers_fetched_at;
+
+DELETE FROM movie_videos WHERE kind <> 'review';
+ALTER TABLE movie_videos DROP COLUMN IF EXISTS published_at;
+ALTER TABLE movie_videos DROP COLUMN IF EXISTS duration;
+ALTER TABLE movie_videos DROP COLUMN IF EXISTS language;
+ALTER TABLE movie_videos DROP COLUMN IF EXISTS source;
+ALTER TABLE movie_videos DROP COLUMN IF EXISTS kind;
+ALTER TABLE IF EXISTS movie_videos RENAME TO movie_reviews;
@@ -0,0 +1,12 @@
+-- Обзоры, трейлеры и тизеры хранятся вместе: kind — вид видео,
+-- source — откуда оно взято (youtube или kinopoisk)
+ALTER TABLE IF EXISTS movie_reviews RENAME TO movie_videos;
+
+ALTER TABLE movie_videos ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'review';
+ALTER TABLE movie_videos ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'youtube';
+ALTER TABLE movie_videos ADD COLUMN IF NOT EXISTS language VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE movie_videos ADD COLUMN IF NOT EXISTS duration INT NOT NULL DEFAULT 0; -- секунды
+ALTER TABLE movie_videos ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+
+-- когда трейлеры фильма последний раз запрашивались; NULL — ещё не запрашивались
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS trailers_fetched_at TIMESTAMP;
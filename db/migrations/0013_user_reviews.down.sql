@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS ratings_reviews_idx;
+ALTER TABLE ratings DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_text;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_title;
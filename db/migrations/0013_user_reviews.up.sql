@@ -0,0 +1,8 @@
+-- Текстовая рецензия пользователя хранится вместе с его оценкой фильма:
+-- удаление оценки удаляет и рецензию
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_text TEXT; -- NULL — оценка без рецензии
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS ratings_reviews_idx ON ratings (movie_id, reviewed_at DESC)
+  WHERE review_text IS NOT NULL;
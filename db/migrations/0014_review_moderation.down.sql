@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS review_reports;
+DROP TABLE IF EXISTS review_votes;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_status;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_spoiler;
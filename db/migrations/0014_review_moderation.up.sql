@@ -0,0 +1,35 @@
+-- Метка спойлера и статус модерации рецензии: published — видна всем,
+-- hidden — скрыта модератором
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_spoiler BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_status VARCHAR(16) NOT NULL DEFAULT 'published';
+
+-- Голоса «полезно / бесполезно»; у рецензии нет своего ID, её ключ — оценка
+-- автора (author_id, movie_id)
+CREATE TABLE IF NOT EXISTS review_votes (
+  voter_id  INT NOT NULL,
+  author_id INT NOT NULL,
+  movie_id  BIGINT NOT NULL,
+  helpful   BOOLEAN NOT NULL,
+  voted_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(voter_id, author_id, movie_id),
+  FOREIGN KEY(voter_id) REFERENCES users(user_id) ON DELETE CASCADE,
+  FOREIGN KEY(author_id, movie_id) REFERENCES ratings(user_id, movie_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS review_votes_review_idx ON review_votes (movie_id, author_id);
+
+-- Жалобы на рецензии; resolved_at — жалоба отклонена модератором
+CREATE TABLE IF NOT EXISTS review_reports (
+  reporter_id INT NOT NULL,
+  author_id   INT NOT NULL,
+  movie_id    BIGINT NOT NULL,
+  reason      TEXT NOT NULL DEFAULT '',
+  reported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  resolved_at TIMESTAMP,
+  PRIMARY KEY(reporter_id, author_id, movie_id),
+  FOREIGN KEY(reporter_id) REFERENCES users(user_id) ON DELETE CASCADE,
+  FOREIGN KEY(author_id, movie_id) REFERENCES ratings(user_id, movie_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS review_reports_open_idx ON review_reports (movie_id, author_id)
+  WHERE resolved_at IS NULL;
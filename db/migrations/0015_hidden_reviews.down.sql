@@ -0,0 +1 @@
+DROP TABLE IF EXISTS hidden_reviews;
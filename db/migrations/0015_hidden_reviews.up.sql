@@ -0,0 +1,14 @@
+-- Решение модератора скрыть рецензию переживает удаление рецензии и оценки:
+-- пока запись есть, автор не может написать рецензию на фильм заново
+CREATE TABLE IF NOT EXISTS hidden_reviews (
+  user_id   INT NOT NULL,
+  movie_id  BIGINT NOT NULL,
+  hidden_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(user_id, movie_id),
+  FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+  FOREIGN KEY(movie_id) REFERENCES movies(movie_id) ON DELETE CASCADE
+);
+
+INSERT INTO hidden_reviews (user_id, movie_id)
+SELECT user_id, movie_id FROM ratings WHERE review_status = 'hidden'
+ON CONFLICT DO NOTHING;
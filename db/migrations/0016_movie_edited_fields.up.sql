@@ -0,0 +1,2 @@
+-- Поля карточки, исправленные модератором: обновление из Kinopoisk их не затирает
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS edited_fields TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,8 @@
+-- Фильмы, удалённые или слитые модератором. Такие ID больше не загружаются из
+-- Kinopoisk, а ID слитого дубля ведёт на целевой фильм (merged_into)
+CREATE TABLE IF NOT EXISTS removed_movies (
+  movie_id    BIGINT PRIMARY KEY,
+  merged_into BIGINT, -- NULL — фильм удалён
+  removed_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY(merged_into) REFERENCES movies(movie_id) ON DELETE SET NULL
+);
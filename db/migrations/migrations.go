@@ -0,0 +1,9 @@
+// Package migrations содержит SQL-миграции схемы БД, встроенные в бинарь.
+//
+// Файлы называются NNNN_name.up.sql / NNNN_name.down.sql, где NNNN — версия.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandler struct {
+	svc *service.Service
+}
+
+func NewAdminHandler(svc *service.Service) *AdminHandler {
+	return &AdminHandler{svc: svc}
+}
+
+// GET /admin/users?page=&size=
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, size := pageParams(r.URL.Query())
+	list, err := h.svc.ListUsers(r.Context(), page, size)
+	if err != nil {
+		writeError(w, r, err, "cannot list users")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// PATCH /admin/users/{userID}
+func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middleware.UserIDKey).(int64)
+	userID, ok := idParam(w, r, chi.URLParam(r, "userID"), "userID")
+	if !ok {
+		return
+	}
+	var req models.UserUpdate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	user, err := h.svc.UpdateUserByAdmin(r.Context(), actorID, userID, req)
+	if err != nil {
+		writeError(w, r, err, "cannot update user")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// PATCH /admin/movies/{id}
+func (h *AdminHandler) UpdateMovie(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req models.MoviePatch
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	movie, err := h.svc.UpdateMovie(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err, "cannot update movie")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie)
+}
+
+// DELETE /admin/movies/{id}
+func (h *AdminHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteMovie(r.Context(), id); err != nil {
+		writeError(w, r, err, "cannot delete movie")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type mergeRequest struct {
+	Into int64 `json:"into"`
+}
+
+func (req *mergeRequest) Validate() error {
+	var v validation.Validator
+	v.ID("into", req.Into)
+	return v.Err()
+}
+
+// POST /admin/movies/{id}/merge — сливает дубль {id} в фильм into
+func (h *AdminHandler) MergeMovie(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req mergeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	movie, err := h.svc.MergeMovies(r.Context(), id, req.Into)
+	if err != nil {
+		writeError(w, r, err, "cannot merge movies")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie)
+}
+
+// POST /admin/movies/{id}/sync — принудительно обновляет фильм из Kinopoisk
+func (h *AdminHandler) ResyncMovie(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	movie, err := h.svc.ResyncMovie(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "cannot sync movie")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movie)
+}
+
+// GET /admin/sync — состояние фонового обновления каталога
+func (h *AdminHandler) SyncStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.svc.SyncStatus(r.Context())
+	if err != nil {
+		writeError(w, r, err, "cannot get sync status")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// POST /admin/sync — внеочередной проход обновления каталога
+func (h *AdminHandler) TriggerSync(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.TriggerCatalogSync(); err != nil {
+		writeError(w, r, err, "cannot start sync")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GET /admin/reviews?status=reported|hidden&page=&size= — очередь модерации рецензий
+func (h *AdminHandler) ListReviewQueue(w http.ResponseWriter, r *http.Request) {
+	page, size := pageParams(r.URL.Query())
+	list, err := h.svc.ListReviewQueue(r.Context(), r.URL.Query().Get("status"), page, size)
+	if err != nil {
+		writeError(w, r, err, "cannot list reviews")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /admin/movies/{id}/reviews/{userID}/hide
+func (h *AdminHandler) HideReview(w http.ResponseWriter, r *http.Request) {
+	movieID, authorID, ok := reviewParams(w, r)
+	if !ok {
+		return
+	}
+	review, err := h.svc.HideUserReview(r.Context(), authorID, movieID)
+	if err != nil {
+		writeError(w, r, err, "cannot hide review")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// POST /admin/movies/{id}/reviews/{userID}/restore — возвращает рецензию и отклоняет жалобы
+func (h *AdminHandler) RestoreReview(w http.ResponseWriter, r *http.Request) {
+	movieID, authorID, ok := reviewParams(w, r)
+	if !ok {
+		return
+	}
+	review, err := h.svc.RestoreUserReview(r.Context(), authorID, movieID)
+	if err != nil {
+		writeError(w, r, err, "cannot restore review")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
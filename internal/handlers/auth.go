@@ -3,8 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
 	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
 )
 
 type AuthHandler struct {
@@ -15,20 +18,89 @@ func NewAuthHandler(svc *service.Service) *AuthHandler {
 	return &AuthHandler{svc: svc}
 }
 
+type credentialsRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Validate для регистрации: при входе длина пароля не проверяется,
+// чтобы не блокировать старые учётные записи
+func (req *credentialsRequest) Validate() error {
+	var v validation.Validator
+	v.Required("email", req.Email)
+	v.Email("email", strings.TrimSpace(req.Email))
+	v.Required("password", req.Password)
+	v.Password("password", req.Password)
+	return v.Err()
+}
+
+type loginRequest struct {
+	credentialsRequest
+}
+
+func (req *loginRequest) Validate() error {
+	var v validation.Validator
+	v.Required("email", req.Email)
+	v.Required("password", req.Password)
+	return v.Err()
+}
+
+type tokenRequest struct {
+	Token string `json:"token"`
+}
+
+func (req *tokenRequest) Validate() error {
+	var v validation.Validator
+	v.Required("token", req.Token)
+	return v.Err()
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (req *refreshRequest) Validate() error {
+	var v validation.Validator
+	v.Required("refresh_token", req.RefreshToken)
+	return v.Err()
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+func (req *forgotPasswordRequest) Validate() error {
+	var v validation.Validator
+	v.Required("email", req.Email)
+	v.Email("email", strings.TrimSpace(req.Email))
+	return v.Err()
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (req *resetPasswordRequest) Validate() error {
+	var v validation.Validator
+	v.Required("token", req.Token)
+	v.Required("password", req.Password)
+	v.Password("password", req.Password)
+	return v.Err()
+}
+
+// POST /auth/register
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	var req credentialsRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	user, err := h.svc.Register(req.Email, req.Password)
+	user, err := h.svc.Register(r.Context(), req.Email, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusConflict)
+		writeError(w, r, err, "cannot register user")
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"user_id": user.ID,
@@ -37,23 +109,93 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// POST /auth/login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+	var req loginRequest
+	if !decodeJSON(w, r, &req) {
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	tokens, err := h.svc.Login(r.Context(), req.Email, req.Password)
+	if err != nil {
+		writeError(w, r, err, "cannot login")
 		return
 	}
-	token, err := h.svc.Login(req.Email, req.Password)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// POST /auth/refresh
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	tokens, err := h.svc.RefreshTokens(r.Context(), req.RefreshToken)
 	if err != nil {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		writeError(w, r, err, "cannot refresh tokens")
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"access_token": token,
-		"token_type":   "Bearer",
-		"expires_in":   3600,
-	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// POST /auth/logout — завершает текущую сессию
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	tok := r.Context().Value(middleware.TokenKey).(middleware.Token)
+	if err := h.svc.Logout(r.Context(), uid, tok.SessionID, tok.ID, tok.ExpiresAt); err != nil {
+		writeError(w, r, err, "cannot logout")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auth/logout-all — завершает все сессии пользователя
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	tok := r.Context().Value(middleware.TokenKey).(middleware.Token)
+	if err := h.svc.LogoutAll(r.Context(), uid, tok.ID, tok.ExpiresAt); err != nil {
+		writeError(w, r, err, "cannot logout")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auth/verify — подтверждение email по токену из письма
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.svc.VerifyEmail(r.Context(), req.Token); err != nil {
+		writeError(w, r, err, "cannot verify email")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auth/password/forgot — отвечает 202 независимо от того, есть ли такой email
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.svc.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		writeError(w, r, err, "cannot send reset email")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// POST /auth/password/reset
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.svc.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		writeError(w, r, err, "cannot reset password")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/problem"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+)
+
+// writeError переводит ошибку сервиса в ответ problem+json. Неизвестные
+// ошибки пишутся в лог, а клиенту уходит 500 с текстом fallback.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var status int
+	switch {
+	case errors.Is(err, context.Canceled) && r.Context().Err() != nil:
+		// клиент закрыл соединение — отвечать некому
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		problem.Error(w, r, "request timed out", http.StatusGatewayTimeout)
+		return
+	case errors.Is(err, service.ErrValidation):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	default:
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		problem.Error(w, r, fallback, http.StatusInternalServerError)
+		return
+	}
+	p := problem.New(status, err.Error())
+	var svcErr *service.Error
+	if errors.As(err, &svcErr) {
+		p.Errors = svcErr.Fields
+	}
+	problem.Write(w, r, p)
+}
+
+// decodeJSON читает тело запроса в dst и, если dst умеет, проверяет его.
+// При ошибке сам отвечает 400 и возвращает false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		problem.Error(w, r, "invalid payload", http.StatusBadRequest)
+		return false
+	}
+	if v, ok := dst.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			writeValidation(w, r, err)
+			return false
+		}
+	}
+	return true
+}
+
+// writeValidation отвечает 400 с ошибками по полям
+func writeValidation(w http.ResponseWriter, r *http.Request, err error) {
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		problem.Validation(w, r, errs)
+		return
+	}
+	problem.Error(w, r, err.Error(), http.StatusBadRequest)
+}
+
+// idParam читает положительный числовой ID из параметра пути
+func idParam(w http.ResponseWriter, r *http.Request, value, field string) (int64, bool) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		problem.Validation(w, r, validation.Errors{{Field: field, Message: "must be a positive integer"}})
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt и queryFloat читают необязательный числовой параметр запроса;
+// ошибка разбора попадает в v
+func queryInt(v *validation.Validator, q url.Values, name string, dst *int) {
+	if s := q.Get(name); s != "" {
+		n, err := strconv.Atoi(s)
+		v.Check(err == nil, name, "must be an integer")
+		*dst = n
+	}
+}
+
+func queryFloat(v *validation.Validator, q url.Values, name string, dst *float64) {
+	if s := q.Get(name); s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		v.Check(err == nil, name, "must be a number")
+		*dst = f
+	}
+}
@@ -3,9 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
 	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -17,32 +21,52 @@ func NewMoviesHandler(svc *service.Service) *MoviesHandler {
 	return &MoviesHandler{svc: svc}
 }
 
-// GET /movies/search?q={query}
+// pageParams читает page и size из запроса
+func pageParams(q url.Values) (int, int) {
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(q.Get("size"))
+	if err != nil || size < 1 {
+		size = 20
+	}
+	return page, size
+}
+
+// GET /movies/search?q={query}&page=&size=&source=local|remote
 func (h *MoviesHandler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
-	if q == "" {
-		http.Error(w, "missing query parameter `q`", http.StatusBadRequest)
+	var v validation.Validator
+	v.Required("q", q)
+	if err := v.Err(); err != nil {
+		writeValidation(w, r, err)
 		return
 	}
-	movies, err := h.svc.SearchMovies(q)
+	page, size := pageParams(r.URL.Query())
+	list, err := h.svc.SearchMovies(r.Context(), q, page, size, r.URL.Query().Get("source"))
 	if err != nil {
-		http.Error(w, "search failed", http.StatusInternalServerError)
+		writeError(w, r, err, "search failed")
 		return
 	}
-	json.NewEncoder(w).Encode(movies)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// movieIDParam читает ID фильма из пути
+func movieIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	return idParam(w, r, chi.URLParam(r, "id"), "id")
 }
 
 // GET /movies/{id}
 func (h *MoviesHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid movie id", http.StatusBadRequest)
+	id, ok := movieIDParam(w, r)
+	if !ok {
 		return
 	}
-	movie, err := h.svc.GetMovie(id)
+	movie, err := h.svc.GetMovie(r.Context(), id)
 	if err != nil {
-		http.Error(w, "movie not found", http.StatusNotFound)
+		writeError(w, r, err, "cannot get movie")
 		return
 	}
 	json.NewEncoder(w).Encode(movie)
@@ -50,53 +74,102 @@ func (h *MoviesHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
 
 // GET /movies/{id}/reviews
 func (h *MoviesHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid movie id", http.StatusBadRequest)
+	id, ok := movieIDParam(w, r)
+	if !ok {
 		return
 	}
-	reviews, err := h.svc.GetMovieReviews(id)
+	reviews, err := h.svc.GetMovieReviews(r.Context(), id)
 	if err != nil {
-		http.Error(w, "cannot fetch reviews", http.StatusInternalServerError)
+		writeError(w, r, err, "cannot fetch reviews")
 		return
 	}
 	json.NewEncoder(w).Encode(reviews)
 }
 
-// GET /movies
+// GET /movies/{id}/videos?kind=trailer|teaser|review
+func (h *MoviesHandler) GetMovieVideos(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	videos, err := h.svc.GetMovieVideos(r.Context(), id, r.URL.Query().Get("kind"))
+	if err != nil {
+		writeError(w, r, err, "cannot fetch videos")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(videos)
+}
+
+// GET /movies/{id}/cast
+func (h *MoviesHandler) GetMovieCast(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	cast, err := h.svc.GetMovieCast(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "cannot get cast")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cast)
+}
+
+// GET /movies?page=&size=&year_from=&year_to=&rating_from=&rating_to=&genre=&country=&type=&person=&sort=&order=
+// person — ID человека или часть его имени
 func (h *MoviesHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
-	// читаем page и size
 	q := r.URL.Query()
-	page, err := strconv.Atoi(q.Get("page"))
-	if err != nil || page < 1 {
-		page = 1
+	page, size := pageParams(q)
+
+	f := models.MovieFilter{
+		Genre:   q.Get("genre"),
+		Country: q.Get("country"),
+		Type:    q.Get("type"),
+		Sort:    q.Get("sort"),
+		Order:   q.Get("order"),
 	}
-	size, err := strconv.Atoi(q.Get("size"))
-	if err != nil || size < 1 {
-		size = 20
+	var v validation.Validator
+	queryInt(&v, q, "year_from", &f.YearFrom)
+	queryInt(&v, q, "year_to", &f.YearTo)
+	queryFloat(&v, q, "rating_from", &f.RatingFrom)
+	queryFloat(&v, q, "rating_to", &f.RatingTo)
+	if person := strings.TrimSpace(q.Get("person")); person != "" {
+		if id, err := strconv.ParseInt(person, 10, 64); err == nil {
+			v.ID("person", id)
+			f.PersonID = id
+		} else {
+			f.PersonName = person
+		}
+	}
+	if err := v.Err(); err != nil {
+		writeValidation(w, r, err)
+		return
 	}
 
-	movies, err := h.svc.ListMovies(page, size)
+	list, err := h.svc.ListMovies(r.Context(), f, page, size)
 	if err != nil {
-		http.Error(w, "failed to list movies", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to list movies")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(movies)
+	json.NewEncoder(w).Encode(list)
 }
 
-// handlers/movies.go (добавить ListPopular)
+// GET /movies/popular?page=&size= (limit — синоним size)
 func (h *MoviesHandler) ListPopular(w http.ResponseWriter, r *http.Request) {
-	limitStr := r.URL.Query().Get("limit")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 10
+	q := r.URL.Query()
+	page, size := pageParams(q)
+	if q.Get("size") == "" {
+		if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+			size = limit
+		}
 	}
-	movies, err := h.svc.ListPopular(limit)
+	list, err := h.svc.ListPopular(r.Context(), page, size)
 	if err != nil {
-		http.Error(w, "failed to list popular movies", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to list popular movies")
 		return
 	}
-	json.NewEncoder(w).Encode(movies)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
 }
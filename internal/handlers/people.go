@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+type PeopleHandler struct {
+	svc *service.Service
+}
+
+func NewPeopleHandler(svc *service.Service) *PeopleHandler {
+	return &PeopleHandler{svc: svc}
+}
+
+// GET /people/search?q={name}&page=&size=
+func (h *PeopleHandler) SearchPeople(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	var v validation.Validator
+	v.Required("q", q)
+	if err := v.Err(); err != nil {
+		writeValidation(w, r, err)
+		return
+	}
+	page, size := pageParams(r.URL.Query())
+	list, err := h.svc.SearchPeople(r.Context(), q, page, size)
+	if err != nil {
+		writeError(w, r, err, "search failed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /people/{id}
+func (h *PeopleHandler) GetPerson(w http.ResponseWriter, r *http.Request) {
+	id, ok := idParam(w, r, chi.URLParam(r, "id"), "id")
+	if !ok {
+		return
+	}
+	person, err := h.svc.GetPerson(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "cannot get person")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(person)
+}
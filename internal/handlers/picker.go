@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+)
+
+type PickerHandler struct {
+	svc *service.Service
+}
+
+func NewPickerHandler(svc *service.Service) *PickerHandler {
+	return &PickerHandler{svc: svc}
+}
+
+// GET /users/me/pick?year_from=&year_to=&min_rating=&genre=&max_runtime=&seed=
+func (h *PickerHandler) PickMovie(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	q := r.URL.Query()
+
+	var f models.PickFilter
+	var v validation.Validator
+	queryInt(&v, q, "year_from", &f.YearFrom)
+	queryInt(&v, q, "year_to", &f.YearTo)
+	queryFloat(&v, q, "min_rating", &f.MinRating)
+	queryInt(&v, q, "max_runtime", &f.MaxRuntime)
+	f.Genre = q.Get("genre")
+
+	var rng *rand.Rand
+	if s := q.Get("seed"); s != "" {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		v.Check(err == nil, "seed", "must be an integer")
+		rng = rand.New(rand.NewSource(seed))
+	}
+	if err := v.Err(); err != nil {
+		writeValidation(w, r, err)
+		return
+	}
+
+	res, err := h.svc.PickMovie(r.Context(), uid, f, rng)
+	if err != nil {
+		writeError(w, r, err, "failed to pick a movie")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -3,11 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
 	"github.com/AlexKeyyyy/movies-picker/internal/models"
 	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -21,24 +21,32 @@ func NewRatingsHandler(svc *service.Service) *RatingsHandler {
 
 // GET /users/{userID}/ratings
 func (h *RatingsHandler) GetRatings(w http.ResponseWriter, r *http.Request) {
-	uid := r.Context().Value(middleware.UserIDKey).(int64)
-	list, err := h.svc.GetRatings(uid)
+	uid := r.Context().Value(middleware.TargetUserIDKey).(int64)
+	list, err := h.svc.GetRatings(r.Context(), uid)
 	if err != nil {
-		http.Error(w, "failed to get ratings", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to get ratings")
 		return
 	}
 	json.NewEncoder(w).Encode(list)
 }
 
+type ratingRequest struct {
+	MovieID int64 `json:"movie_id"`
+	Rating  int   `json:"rating"`
+}
+
+func (req *ratingRequest) Validate() error {
+	var v validation.Validator
+	v.ID("movie_id", req.MovieID)
+	v.Range("rating", req.Rating, 1, 10)
+	return v.Err()
+}
+
 // POST /users/{userID}/ratings
 func (h *RatingsHandler) AddOrUpdateRating(w http.ResponseWriter, r *http.Request) {
-	uid := r.Context().Value(middleware.UserIDKey).(int64)
-	var req struct {
-		MovieID int64 `json:"movie_id"`
-		Rating  int   `json:"rating"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	uid := r.Context().Value(middleware.TargetUserIDKey).(int64)
+	var req ratingRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 	item := &models.RatingItem{
@@ -46,20 +54,23 @@ func (h *RatingsHandler) AddOrUpdateRating(w http.ResponseWriter, r *http.Reques
 		MovieID: req.MovieID,
 		Rating:  req.Rating,
 	}
-	if err := h.svc.UpsertRating(item); err != nil {
-		http.Error(w, "cannot set rating", http.StatusInternalServerError)
+	if err := h.svc.UpsertRating(r.Context(), item); err != nil {
+		writeError(w, r, err, "cannot set rating")
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(item)
 }
 
-// handlers/ratings.go (добавить DeleteRating)
+// DELETE /users/{userID}/ratings/{movieID}
 func (h *RatingsHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
-	userID, _ := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
-	movieID, _ := strconv.ParseInt(chi.URLParam(r, "movieID"), 10, 64)
-	if err := h.svc.DeleteRating(userID, movieID); err != nil {
-		http.Error(w, "failed to delete rating", http.StatusInternalServerError)
+	uid := r.Context().Value(middleware.TargetUserIDKey).(int64)
+	movieID, ok := idParam(w, r, chi.URLParam(r, "movieID"), "movieID")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteRating(r.Context(), uid, movieID); err != nil {
+		writeError(w, r, err, "failed to delete rating")
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+)
+
+type RecommendationsHandler struct {
+	svc *service.Service
+}
+
+func NewRecommendationsHandler(svc *service.Service) *RecommendationsHandler {
+	return &RecommendationsHandler{svc: svc}
+}
+
+// GET /users/me/recommendations?limit={n}
+func (h *RecommendationsHandler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	recs, err := h.svc.GetRecommendations(r.Context(), uid, limit)
+	if err != nil {
+		writeError(w, r, err, "failed to build recommendations")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recs)
+}
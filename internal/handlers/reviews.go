@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// Ограничения длины рецензии и жалобы в символах
+const (
+	maxReviewTitle  = 200
+	maxReviewText   = 10000
+	maxReportReason = 500
+)
+
+type ReviewsHandler struct {
+	svc *service.Service
+}
+
+func NewReviewsHandler(svc *service.Service) *ReviewsHandler {
+	return &ReviewsHandler{svc: svc}
+}
+
+// reviewParams читает из пути ID фильма и автора рецензии
+func reviewParams(w http.ResponseWriter, r *http.Request) (movieID, authorID int64, ok bool) {
+	if movieID, ok = movieIDParam(w, r); !ok {
+		return 0, 0, false
+	}
+	authorID, ok = idParam(w, r, chi.URLParam(r, "userID"), "userID")
+	return movieID, authorID, ok
+}
+
+// GET /movies/{id}/user-reviews?sort=helpful|newest&page=&size=
+func (h *ReviewsHandler) ListUserReviews(w http.ResponseWriter, r *http.Request) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	page, size := pageParams(r.URL.Query())
+	list, err := h.svc.ListUserReviews(r.Context(), id, r.URL.Query().Get("sort"), page, size)
+	if err != nil {
+		writeError(w, r, err, "cannot list reviews")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+type reviewRequest struct {
+	Rating  int    `json:"rating"` // 0 — оставить текущую оценку
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+	Spoiler bool   `json:"spoiler"`
+}
+
+func (req *reviewRequest) Validate() error {
+	var v validation.Validator
+	if req.Rating != 0 {
+		v.Range("rating", req.Rating, 1, 10)
+	}
+	v.MaxLength("title", strings.TrimSpace(req.Title), maxReviewTitle)
+	v.Required("text", req.Text)
+	v.MaxLength("text", strings.TrimSpace(req.Text), maxReviewText)
+	return v.Err()
+}
+
+// decodeReview читает рецензию текущего пользователя на фильм из пути
+func decodeReview(w http.ResponseWriter, r *http.Request) (*models.UserReview, bool) {
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return nil, false
+	}
+	var req reviewRequest
+	if !decodeJSON(w, r, &req) {
+		return nil, false
+	}
+	return &models.UserReview{
+		UserID:  r.Context().Value(middleware.UserIDKey).(int64),
+		MovieID: id,
+		Rating:  req.Rating,
+		Title:   strings.TrimSpace(req.Title),
+		Text:    strings.TrimSpace(req.Text),
+		Spoiler: req.Spoiler,
+	}, true
+}
+
+// GET /movies/{id}/my-review
+func (h *ReviewsHandler) GetMyReview(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	review, err := h.svc.GetUserReview(r.Context(), uid, id)
+	if err != nil {
+		writeError(w, r, err, "cannot get review")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// POST /movies/{id}/my-review
+func (h *ReviewsHandler) CreateMyReview(w http.ResponseWriter, r *http.Request) {
+	review, ok := decodeReview(w, r)
+	if !ok {
+		return
+	}
+	saved, err := h.svc.CreateUserReview(r.Context(), review)
+	if err != nil {
+		writeError(w, r, err, "cannot create review")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// PUT /movies/{id}/my-review
+func (h *ReviewsHandler) UpdateMyReview(w http.ResponseWriter, r *http.Request) {
+	review, ok := decodeReview(w, r)
+	if !ok {
+		return
+	}
+	saved, err := h.svc.UpdateUserReview(r.Context(), review)
+	if err != nil {
+		writeError(w, r, err, "cannot update review")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// DELETE /movies/{id}/my-review — оценка фильма остаётся
+func (h *ReviewsHandler) DeleteMyReview(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	id, ok := movieIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteUserReview(r.Context(), uid, id); err != nil {
+		writeError(w, r, err, "cannot delete review")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type reviewVoteRequest struct {
+	Helpful *bool `json:"helpful"`
+}
+
+func (req *reviewVoteRequest) Validate() error {
+	var v validation.Validator
+	v.Check(req.Helpful != nil, "helpful", "is required")
+	return v.Err()
+}
+
+// PUT /movies/{id}/user-reviews/{userID}/vote
+func (h *ReviewsHandler) VoteReview(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	movieID, authorID, ok := reviewParams(w, r)
+	if !ok {
+		return
+	}
+	var req reviewVoteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.svc.VoteUserReview(r.Context(), uid, authorID, movieID, *req.Helpful); err != nil {
+		writeError(w, r, err, "cannot vote for review")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /movies/{id}/user-reviews/{userID}/vote
+func (h *ReviewsHandler) DeleteReviewVote(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	movieID, authorID, ok := reviewParams(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteReviewVote(r.Context(), uid, authorID, movieID); err != nil {
+		writeError(w, r, err, "cannot remove vote")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type reportRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (req *reportRequest) Validate() error {
+	var v validation.Validator
+	v.MaxLength("reason", strings.TrimSpace(req.Reason), maxReportReason)
+	return v.Err()
+}
+
+// POST /movies/{id}/user-reviews/{userID}/report
+func (h *ReviewsHandler) ReportReview(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	movieID, authorID, ok := reviewParams(w, r)
+	if !ok {
+		return
+	}
+	var req reportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.svc.ReportUserReview(r.Context(), uid, authorID, movieID, strings.TrimSpace(req.Reason)); err != nil {
+		writeError(w, r, err, "cannot report review")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
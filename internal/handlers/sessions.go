@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionsHandler struct {
+	svc *service.Service
+}
+
+func NewSessionsHandler(svc *service.Service) *SessionsHandler {
+	return &SessionsHandler{svc: svc}
+}
+
+type createSessionRequest struct {
+	Title  string   `json:"title"`
+	Emails []string `json:"emails"`
+}
+
+func (req *createSessionRequest) Validate() error {
+	var v validation.Validator
+	v.MaxLength("title", req.Title, 255)
+	for i, e := range req.Emails {
+		v.Email(fmt.Sprintf("emails[%d]", i), strings.TrimSpace(e))
+	}
+	return v.Err()
+}
+
+type voteRequest struct {
+	MovieID int64  `json:"movie_id"`
+	Kind    string `json:"kind"`
+}
+
+func (req *voteRequest) Validate() error {
+	var v validation.Validator
+	v.ID("movie_id", req.MovieID)
+	v.OneOf("kind", req.Kind, "up", "down", "veto")
+	return v.Err()
+}
+
+func sessionIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	return idParam(w, r, chi.URLParam(r, "sessionID"), "sessionID")
+}
+
+// POST /sessions
+func (h *SessionsHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	var req createSessionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	session, err := h.svc.CreateSession(r.Context(), uid, req.Title, req.Emails)
+	if err != nil {
+		writeError(w, r, err, "cannot create session")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// GET /sessions/{sessionID}
+func (h *SessionsHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	sid, ok := sessionIDParam(w, r)
+	if !ok {
+		return
+	}
+	session, err := h.svc.GetSession(r.Context(), uid, sid)
+	if err != nil {
+		writeError(w, r, err, "cannot get session")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// POST /sessions/{sessionID}/join
+func (h *SessionsHandler) JoinSession(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	sid, ok := sessionIDParam(w, r)
+	if !ok {
+		return
+	}
+	session, err := h.svc.JoinSession(r.Context(), uid, sid)
+	if err != nil {
+		writeError(w, r, err, "cannot join session")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// POST /sessions/{sessionID}/votes
+func (h *SessionsHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	sid, ok := sessionIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req voteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := h.svc.VoteInSession(r.Context(), uid, sid, req.MovieID, req.Kind); err != nil {
+		writeError(w, r, err, "cannot save vote")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /sessions/{sessionID}/result?limit={n}
+func (h *SessionsHandler) GetResult(w http.ResponseWriter, r *http.Request) {
+	uid := r.Context().Value(middleware.UserIDKey).(int64)
+	sid, ok := sessionIDParam(w, r)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	res, err := h.svc.GetSessionResult(r.Context(), uid, sid, limit)
+	if err != nil {
+		writeError(w, r, err, "cannot compute session result")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -3,9 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
 	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
 )
 
 type UserHandler struct {
@@ -19,29 +21,38 @@ func NewUserHandler(svc *service.Service) *UserHandler {
 // GET /users/me
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(int64)
-	user, err := h.svc.GetProfile(userID)
+	user, err := h.svc.GetProfile(r.Context(), userID)
 	if err != nil {
-		http.Error(w, "profile not found", http.StatusNotFound)
+		writeError(w, r, err, "cannot get profile")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
 
+type updateProfileRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (req *updateProfileRequest) Validate() error {
+	var v validation.Validator
+	v.Email("email", strings.TrimSpace(req.Email))
+	v.Password("password", req.Password)
+	return v.Err()
+}
+
 // PATCH /users/me
 func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(int64)
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	var req updateProfileRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	user, err := h.svc.UpdateProfile(userID, req.Email, req.Password)
+	tok := r.Context().Value(middleware.TokenKey).(middleware.Token)
+	user, err := h.svc.UpdateProfile(r.Context(), userID, tok.SessionID, req.Email, req.Password)
 	if err != nil {
-		http.Error(w, "update failed", http.StatusInternalServerError)
+		writeError(w, r, err, "update failed")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -2,12 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
 	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -21,28 +20,34 @@ func NewWatchlistHandler(svc *service.Service) *WatchlistHandler {
 
 // GET /users/{userID}/watchlist
 func (h *WatchlistHandler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
-	uid := r.Context().Value(middleware.UserIDKey).(int64)
-	log.Println(uid)
-	list, err := h.svc.GetWatchlist(uid)
+	uid := r.Context().Value(middleware.TargetUserIDKey).(int64)
+	list, err := h.svc.GetWatchlist(r.Context(), uid)
 	if err != nil {
-		http.Error(w, "failed to get watchlist", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to get watchlist")
 		return
 	}
 	json.NewEncoder(w).Encode(list)
 }
 
+type watchlistRequest struct {
+	MovieID int64 `json:"movie_id"`
+}
+
+func (req *watchlistRequest) Validate() error {
+	var v validation.Validator
+	v.ID("movie_id", req.MovieID)
+	return v.Err()
+}
+
 // POST /users/{userID}/watchlist
 func (h *WatchlistHandler) AddToWatchlist(w http.ResponseWriter, r *http.Request) {
-	uid := r.Context().Value(middleware.UserIDKey).(int64)
-	var req struct {
-		MovieID int64 `json:"movie_id"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	uid := r.Context().Value(middleware.TargetUserIDKey).(int64)
+	var req watchlistRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	if err := h.svc.AddToWatchlist(uid, req.MovieID); err != nil {
-		http.Error(w, "cannot add to watchlist", http.StatusInternalServerError)
+	if err := h.svc.AddToWatchlist(r.Context(), uid, req.MovieID); err != nil {
+		writeError(w, r, err, "cannot add to watchlist")
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -54,15 +59,13 @@ func (h *WatchlistHandler) AddToWatchlist(w http.ResponseWriter, r *http.Request
 
 // DELETE /users/{userID}/watchlist/{movieID}
 func (h *WatchlistHandler) RemoveFromWatchlist(w http.ResponseWriter, r *http.Request) {
-	uid := r.Context().Value(middleware.UserIDKey).(int64)
-	midStr := chi.URLParam(r, "movieID")
-	mid, err := strconv.ParseInt(midStr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid movie id", http.StatusBadRequest)
+	uid := r.Context().Value(middleware.TargetUserIDKey).(int64)
+	mid, ok := idParam(w, r, chi.URLParam(r, "movieID"), "movieID")
+	if !ok {
 		return
 	}
-	if err := h.svc.RemoveFromWatchlist(uid, mid); err != nil {
-		http.Error(w, "cannot remove from watchlist", http.StatusInternalServerError)
+	if err := h.svc.RemoveFromWatchlist(r.Context(), uid, mid); err != nil {
+		writeError(w, r, err, "cannot remove from watchlist")
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
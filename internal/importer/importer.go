@@ -0,0 +1,282 @@
+// Package importer загружает фильмы из Kinopoisk в каталог. Прогресс каждого
+// источника сохраняется в БД, так что прерванный импорт продолжается с места
+// остановки, а завершённый не запрашивает Kinopoisk повторно.
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/repository"
+	"github.com/AlexKeyyyy/movies-picker/internal/service"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// Store — каталог фильмов и чекпоинты импорта
+type Store interface {
+	UpsertMovie(ctx context.Context, m *models.Movie) error
+	GetImportJob(ctx context.Context, source string) (*models.ImportJob, error)
+	SaveImportJob(ctx context.Context, source string, totalPages int) error
+	FinishImportJob(ctx context.Context, source string) error
+	ResetImportJob(ctx context.Context, source string) error
+	ListDoneImportUnits(ctx context.Context, source string) ([]int64, error)
+	SaveImportUnit(ctx context.Context, source string, unit int64, movies int, errMsg string) error
+	ReplaceMovieCast(ctx context.Context, movieID int64, cast []models.CastMember) error
+}
+
+// Staff — загрузка съёмочной группы фильма из Kinopoisk
+type Staff interface {
+	GetStaff(ctx context.Context, filmID int64) ([]kinopoisk.StaffMember, error)
+}
+
+// Options — настройки импорта
+type Options struct {
+	Workers int           // сколько единиц загружается параллельно
+	Retries int           // повторы неудачной единицы за один запуск
+	Backoff time.Duration // пауза перед первым повтором, дальше удваивается
+	DryRun  bool          // только посчитать, что осталось загрузить, без записи в БД
+	Restart bool          // забыть чекпоинт и загрузить источник заново
+	Staff   Staff         // если задан, вместе с фильмами загружается их состав
+}
+
+type Importer struct {
+	store Store
+	opts  Options
+}
+
+func New(store Store, opts Options) *Importer {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	return &Importer{store: store, opts: opts}
+}
+
+// unitResult — итог загрузки одной единицы работы
+type unitResult struct {
+	movies  int
+	total   int // число страниц, которое вернул Kinopoisk
+	retries int
+	missing bool // фильма из списка нет в Kinopoisk
+	err     error
+}
+
+// Run импортирует источник и возвращает отчёт. Ошибка означает, что импорт
+// не удалось начать; неудачные единицы попадают в Report.Failed и будут
+// повторены при следующем запуске.
+func (im *Importer) Run(ctx context.Context, src Source) (*Report, error) {
+	start := time.Now()
+	name := src.Name()
+	rep := &Report{Source: name, DryRun: im.opts.DryRun}
+	defer func() { rep.Elapsed = time.Since(start) }()
+
+	totalPages, done, err := im.checkpoint(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !im.opts.DryRun {
+		if err := im.store.SaveImportJob(ctx, name, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	// у постраничного источника сначала нужна первая страница: в ней число страниц
+	first := false
+	if src.Paged() && totalPages == 0 {
+		res := im.load(ctx, src, 1)
+		if res.err != nil || !im.opts.DryRun {
+			rep.add(1, res)
+		}
+		if res.err != nil {
+			rep.Units = 1
+			rep.Interrupted = ctx.Err() != nil
+			if fatal(res.err) {
+				rep.Stopped = res.err.Error()
+			}
+			return rep, nil
+		}
+		totalPages = res.total
+		first = !im.opts.DryRun
+		if !im.opts.DryRun {
+			if err := im.store.SaveImportJob(ctx, name, totalPages); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var pending []int64
+	units := src.Units(totalPages)
+	for _, u := range units {
+		switch {
+		case first && u == 1:
+		case done[u]:
+			rep.Skipped++
+		default:
+			pending = append(pending, u)
+		}
+	}
+	rep.Units = len(units)
+	if im.opts.DryRun {
+		rep.Pending = len(pending)
+		return rep, nil
+	}
+
+	im.runWorkers(ctx, src, pending, rep)
+
+	rep.Interrupted = ctx.Err() != nil
+	if !rep.Interrupted && rep.Stopped == "" && len(rep.Failed) == 0 {
+		if err := im.store.FinishImportJob(ctx, name); err != nil {
+			return rep, err
+		}
+	}
+	return rep, nil
+}
+
+// checkpoint читает число страниц и уже загруженные единицы источника
+func (im *Importer) checkpoint(ctx context.Context, name string) (int, map[int64]bool, error) {
+	done := make(map[int64]bool)
+	if im.opts.Restart {
+		if im.opts.DryRun {
+			return 0, done, nil
+		}
+		return 0, done, im.store.ResetImportJob(ctx, name)
+	}
+	job, err := im.store.GetImportJob(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, done, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	units, err := im.store.ListDoneImportUnits(ctx, name)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, u := range units {
+		done[u] = true
+	}
+	return job.TotalPages, done, nil
+}
+
+// runWorkers загружает единицы не более чем в opts.Workers потоков. После
+// отмены ctx или исчерпания квоты Kinopoisk новые единицы не берутся.
+func (im *Importer) runWorkers(ctx context.Context, src Source, units []int64, rep *Report) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	queue := make(chan int64)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < im.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range queue {
+				res := im.load(ctx, src, u)
+				mu.Lock()
+				rep.add(u, res)
+				if fatal(res.err) && rep.Stopped == "" {
+					rep.Stopped = res.err.Error()
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, u := range units {
+		select {
+		case queue <- u:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// load загружает единицу с повторами и сохраняет фильмы и чекпоинт
+func (im *Importer) load(ctx context.Context, src Source, unit int64) unitResult {
+	var res unitResult
+	var films []kinopoisk.Film
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() == nil {
+			films, res.total, res.err = src.Fetch(ctx, unit)
+		}
+		if ctx.Err() != nil {
+			// прервано: единица не загружена, но и неудачей не считается
+			res.err = ctx.Err()
+			return res
+		}
+		if res.err == nil || errors.Is(res.err, kinopoisk.ErrNotFound) || fatal(res.err) || attempt >= im.opts.Retries {
+			break
+		}
+		res.retries++
+		select {
+		case <-time.After(im.opts.Backoff << attempt):
+		case <-ctx.Done():
+			res.err = ctx.Err()
+			return res
+		}
+	}
+	if errors.Is(res.err, kinopoisk.ErrNotFound) {
+		res.missing, res.err = true, nil
+	}
+
+	if res.err == nil && !im.opts.DryRun {
+		for _, f := range films {
+			m := service.MovieFromFilm(f)
+			err := im.store.UpsertMovie(ctx, &m)
+			if errors.Is(err, repository.ErrMovieRemoved) {
+				continue // удалён модератором
+			}
+			if err != nil {
+				res.err = err
+				break
+			}
+			if err := im.loadCast(ctx, m.ID); err != nil {
+				res.err = err
+				break
+			}
+		}
+	}
+	if res.err == nil {
+		res.movies = len(films)
+	}
+	if res.err != nil {
+		log.Printf("import %s: unit %d: %v", src.Name(), unit, res.err)
+	}
+
+	if !im.opts.DryRun {
+		errMsg := ""
+		if res.err != nil {
+			errMsg = res.err.Error()
+		}
+		if err := im.store.SaveImportUnit(ctx, src.Name(), unit, res.movies, errMsg); err != nil && res.err == nil {
+			res.err = err
+		}
+	}
+	return res
+}
+
+// loadCast загружает и сохраняет состав фильма, если это включено в Options
+func (im *Importer) loadCast(ctx context.Context, movieID int64) error {
+	if im.opts.Staff == nil {
+		return nil
+	}
+	staff, err := im.opts.Staff.GetStaff(ctx, movieID)
+	if err != nil && !errors.Is(err, kinopoisk.ErrNotFound) {
+		return err
+	}
+	return im.store.ReplaceMovieCast(ctx, movieID, service.CastFromStaff(staff))
+}
+
+// fatal — ошибка, после которой продолжать импорт бессмысленно: все следующие
+// запросы к Kinopoisk тоже завершатся ею
+func fatal(err error) bool {
+	return errors.Is(err, kinopoisk.ErrQuotaExceeded) || errors.Is(err, kinopoisk.ErrUnauthorized)
+}
@@ -0,0 +1,311 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/repository"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore — чекпоинты и каталог в памяти
+type memStore struct {
+	mu      sync.Mutex
+	movies  map[int64]bool
+	removed map[int64]bool // удалены модератором
+	jobs    map[string]*models.ImportJob
+	done    map[string]map[int64]bool
+	cast    map[int64][]models.CastMember
+	writes  int
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		movies: make(map[int64]bool),
+		jobs:   make(map[string]*models.ImportJob),
+		done:   make(map[string]map[int64]bool),
+		cast:   make(map[int64][]models.CastMember),
+	}
+}
+
+func (s *memStore) UpsertMovie(ctx context.Context, m *models.Movie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.removed[m.ID] {
+		return repository.ErrMovieRemoved
+	}
+	s.movies[m.ID] = true
+	s.writes++
+	return nil
+}
+
+func (s *memStore) GetImportJob(ctx context.Context, source string) (*models.ImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[source]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return j, nil
+}
+
+func (s *memStore) SaveImportJob(ctx context.Context, source string, totalPages int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	j, ok := s.jobs[source]
+	if !ok {
+		j = &models.ImportJob{Source: source}
+		s.jobs[source] = j
+		s.done[source] = make(map[int64]bool)
+	}
+	if totalPages > 0 {
+		j.TotalPages = totalPages
+	}
+	return nil
+}
+
+func (s *memStore) FinishImportJob(ctx context.Context, source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	return nil
+}
+
+func (s *memStore) ResetImportJob(ctx context.Context, source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, source)
+	delete(s.done, source)
+	return nil
+}
+
+func (s *memStore) ListDoneImportUnits(ctx context.Context, source string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var units []int64
+	for u := range s.done[source] {
+		units = append(units, u)
+	}
+	return units, nil
+}
+
+func (s *memStore) SaveImportUnit(ctx context.Context, source string, unit int64, movies int, errMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	if errMsg == "" {
+		s.done[source][unit] = true
+	}
+	return nil
+}
+
+func (s *memStore) ReplaceMovieCast(ctx context.Context, movieID int64, cast []models.CastMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	s.cast[movieID] = cast
+	return nil
+}
+
+// fakeKP отдаёт подборку из pages страниц по 2 фильма; failures[page] —
+// сколько раз подряд страница ответит ошибкой 500
+type fakeKP struct {
+	mu       sync.Mutex
+	pages    int
+	failures map[int]int
+	quota    int // страницы после quota отвечают ErrQuotaExceeded
+	calls    map[int]int
+}
+
+func (k *fakeKP) GetCollection(ctx context.Context, collection string, page int) ([]kinopoisk.Film, int, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.calls == nil {
+		k.calls = make(map[int]int)
+	}
+	k.calls[page]++
+	if page > k.quota && k.quota > 0 {
+		return nil, 0, kinopoisk.ErrQuotaExceeded
+	}
+	if k.failures[page] > 0 {
+		k.failures[page]--
+		return nil, 0, &kinopoisk.StatusError{StatusCode: 500}
+	}
+	films := []kinopoisk.Film{
+		{KinopoiskID: int64(page*10 + 1)},
+		{KinopoiskID: int64(page*10 + 2)},
+	}
+	return films, k.pages, nil
+}
+
+func (k *fakeKP) SearchByKeyword(ctx context.Context, keyword string, page int) ([]kinopoisk.Film, int, error) {
+	return k.GetCollection(ctx, keyword, page)
+}
+
+func (k *fakeKP) GetFilm(ctx context.Context, id int64) (*kinopoisk.Film, error) {
+	if id == 404 {
+		return nil, kinopoisk.ErrNotFound
+	}
+	return &kinopoisk.Film{NameRu: "film"}, nil
+}
+
+// GetStaff отдаёт одного режиссёра; у фильма 22 данных о съёмочной группе нет
+func (k *fakeKP) GetStaff(ctx context.Context, filmID int64) ([]kinopoisk.StaffMember, error) {
+	if filmID == 22 {
+		return nil, kinopoisk.ErrNotFound
+	}
+	return []kinopoisk.StaffMember{
+		{StaffID: filmID * 100, NameRu: "Режиссёр", ProfessionKey: kinopoisk.ProfessionDirector},
+	}, nil
+}
+
+func (k *fakeKP) totalCalls() int {
+	n := 0
+	for _, c := range k.calls {
+		n += c
+	}
+	return n
+}
+
+func TestRun_ResumesFromCheckpoint(t *testing.T) {
+	store := newMemStore()
+	kp := &fakeKP{pages: 5, failures: map[int]int{3: 10}}
+	im := New(store, Options{Workers: 3, Retries: 1})
+
+	rep, err := im.Run(context.Background(), Collection(kp, "TOP_250_MOVIES"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, rep.Units)
+	assert.Equal(t, 4, rep.Done)
+	assert.Equal(t, []int64{3}, rep.Failed)
+	assert.Equal(t, 1, rep.Retries)
+	assert.Equal(t, 8, rep.Movies)
+
+	// второй запуск загружает только упавшую страницу
+	kp.failures = nil
+	kp.calls = nil
+	rep, err = im.Run(context.Background(), Collection(kp, "TOP_250_MOVIES"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, rep.Skipped)
+	assert.Equal(t, 1, rep.Done)
+	assert.Empty(t, rep.Failed)
+	assert.Equal(t, map[int]int{3: 1}, kp.calls)
+	assert.Len(t, store.movies, 10)
+
+	// завершённый источник больше не запрашивает Kinopoisk
+	kp.calls = nil
+	rep, err = im.Run(context.Background(), Collection(kp, "TOP_250_MOVIES"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, rep.Skipped)
+	assert.Zero(t, kp.totalCalls())
+}
+
+func TestRun_StopsOnQuota(t *testing.T) {
+	store := newMemStore()
+	kp := &fakeKP{pages: 10, quota: 3}
+
+	rep, err := New(store, Options{Retries: 2}).Run(context.Background(), Collection(kp, "TOP_250_MOVIES"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, rep.Done)
+	assert.Equal(t, []int64{4}, rep.Failed)
+	assert.Equal(t, kinopoisk.ErrQuotaExceeded.Error(), rep.Stopped)
+	assert.Zero(t, rep.Retries, "quota errors are not retried")
+	assert.Equal(t, 4, kp.totalCalls())
+
+	// на следующий день импорт продолжается с четвёртой страницы
+	kp.quota, kp.calls = 0, nil
+	rep, err = New(store, Options{}).Run(context.Background(), Collection(kp, "TOP_250_MOVIES"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, rep.Skipped)
+	assert.Equal(t, 7, rep.Done)
+	assert.Empty(t, rep.Stopped)
+}
+
+func TestRun_Restart(t *testing.T) {
+	store := newMemStore()
+	kp := &fakeKP{pages: 2}
+	_, err := New(store, Options{}).Run(context.Background(), Collection(kp, "TOP_POPULAR_ALL"))
+	require.NoError(t, err)
+
+	rep, err := New(store, Options{Restart: true}).Run(context.Background(), Collection(kp, "TOP_POPULAR_ALL"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, rep.Done)
+	assert.Zero(t, rep.Skipped)
+}
+
+func TestRun_DryRun(t *testing.T) {
+	store := newMemStore()
+	kp := &fakeKP{pages: 4}
+
+	rep, err := New(store, Options{DryRun: true}).Run(context.Background(), Keyword(kp, "матрица"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, rep.Units)
+	assert.Equal(t, 4, rep.Pending)
+	assert.Zero(t, rep.Done)
+	assert.Zero(t, store.writes)
+	assert.Equal(t, 1, kp.totalCalls(), "only the first page to learn the page count")
+}
+
+func TestRun_IDs(t *testing.T) {
+	store := newMemStore()
+	kp := &fakeKP{}
+
+	rep, err := New(store, Options{Workers: 2}).Run(context.Background(), IDs(kp, "films.txt", []int64{301, 404, 326}))
+	require.NoError(t, err)
+	assert.Equal(t, 3, rep.Units)
+	assert.Equal(t, 3, rep.Done)
+	assert.Equal(t, 1, rep.Missing)
+	assert.Equal(t, map[int64]bool{301: true, 326: true}, store.movies)
+}
+
+func TestRun_SkipsRemovedMovies(t *testing.T) {
+	store := newMemStore()
+	store.removed = map[int64]bool{326: true}
+	kp := &fakeKP{}
+
+	rep, err := New(store, Options{}).Run(context.Background(), IDs(kp, "films.txt", []int64{301, 326}))
+	require.NoError(t, err)
+	assert.Equal(t, 2, rep.Done)
+	assert.Empty(t, rep.Failed)
+	assert.Equal(t, map[int64]bool{301: true}, store.movies)
+}
+
+func TestRun_People(t *testing.T) {
+	store := newMemStore()
+	kp := &fakeKP{pages: 2}
+
+	rep, err := New(store, Options{Staff: kp}).Run(context.Background(), Collection(kp, "TOP_250_MOVIES"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, rep.Done)
+	assert.Len(t, store.cast, 4)
+	assert.Equal(t, []models.CastMember{{PersonID: 1100, Name: "Режиссёр", Profession: kinopoisk.ProfessionDirector}}, store.cast[11])
+	assert.Empty(t, store.cast[22], "films without staff are saved with an empty cast")
+}
+
+func TestRun_CheckpointError(t *testing.T) {
+	store := &failingStore{memStore: newMemStore()}
+	_, err := New(store, Options{}).Run(context.Background(), Collection(&fakeKP{pages: 1}, "TOP_250_MOVIES"))
+	assert.Error(t, err)
+}
+
+type failingStore struct{ *memStore }
+
+func (s *failingStore) GetImportJob(context.Context, string) (*models.ImportJob, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestReadIDs(t *testing.T) {
+	ids, err := ReadIDs(strings.NewReader("301\n\n# комментарий\n326 # Побег из Шоушенка\n301\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{301, 326}, ids)
+
+	_, err = ReadIDs(strings.NewReader("301\nabc\n"))
+	assert.EqualError(t, err, `line 2: invalid film id "abc"`)
+}
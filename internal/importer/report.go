@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report — итог импорта одного источника
+type Report struct {
+	Source      string
+	DryRun      bool
+	Units       int     // всего единиц работы (страниц или фильмов)
+	Skipped     int     // загружены в прошлых запусках
+	Pending     int     // dry-run: осталось загрузить
+	Done        int     // загружены в этом запуске
+	Failed      []int64 // не загрузились и после повторов
+	Movies      int     // сохранено фильмов
+	Missing     int     // фильмов из списка нет в Kinopoisk
+	Retries     int
+	Interrupted bool
+	Stopped     string // причина остановки, например исчерпанная квота Kinopoisk
+	Elapsed     time.Duration
+}
+
+func (r *Report) add(unit int64, res unitResult) {
+	r.Retries += res.retries
+	if errors.Is(res.err, context.Canceled) {
+		return // не загружена из-за остановки, будет загружена при следующем запуске
+	}
+	if res.err != nil {
+		r.Failed = append(r.Failed, unit)
+		return
+	}
+	r.Done++
+	r.Movies += res.movies
+	if res.missing {
+		r.Missing++
+	}
+}
+
+// Print выводит отчёт в читаемом виде
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "%s\n", r.Source)
+	if r.DryRun {
+		fmt.Fprintf(w, "  dry run: %d units, %d already imported, %d to import\n", r.Units, r.Skipped, r.Pending)
+		if len(r.Failed) > 0 {
+			fmt.Fprintf(w, "  failed to read the source: %s\n", units(r.Failed))
+		}
+		return
+	}
+	fmt.Fprintf(w, "  units:    %d (%d already imported)\n", r.Units, r.Skipped)
+	fmt.Fprintf(w, "  imported: %d units, %d movies\n", r.Done, r.Movies)
+	if r.Missing > 0 {
+		fmt.Fprintf(w, "  missing:  %d films not found in Kinopoisk\n", r.Missing)
+	}
+	if len(r.Failed) > 0 {
+		fmt.Fprintf(w, "  failed:   %d (%s), will be retried on the next run\n", len(r.Failed), units(r.Failed))
+	}
+	fmt.Fprintf(w, "  retries:  %d\n", r.Retries)
+	if r.Stopped != "" {
+		fmt.Fprintf(w, "  stopped:  %s; progress is saved, rerun to continue\n", r.Stopped)
+	} else if r.Interrupted {
+		fmt.Fprintln(w, "  interrupted: progress is saved, rerun to continue")
+	}
+	fmt.Fprintf(w, "  elapsed:  %s\n", r.Elapsed.Round(time.Millisecond))
+}
+
+func units(ids []int64) string {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	parts := make([]string, len(sorted))
+	for i, id := range sorted {
+		parts[i] = fmt.Sprint(id)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// Kinopoisk — методы клиента Kinopoisk, нужные импортёру
+type Kinopoisk interface {
+	GetCollection(ctx context.Context, collection string, page int) ([]kinopoisk.Film, int, error)
+	SearchByKeyword(ctx context.Context, keyword string, page int) ([]kinopoisk.Film, int, error)
+	GetFilm(ctx context.Context, id int64) (*kinopoisk.Film, error)
+}
+
+// Source — что импортировать. Работа делится на единицы (страницы подборки
+// или ID фильмов), прогресс по которым сохраняется в чекпоинте.
+type Source interface {
+	// Name — ключ чекпоинта, например collection:TOP_250_MOVIES
+	Name() string
+	// Paged — число единиц становится известно только после первой страницы
+	Paged() bool
+	// Units перечисляет единицы работы; totalPages — число страниц (для Paged)
+	Units(totalPages int) []int64
+	// Fetch загружает одну единицу и возвращает фильмы и число страниц
+	Fetch(ctx context.Context, unit int64) ([]kinopoisk.Film, int, error)
+}
+
+// pages — источник, который отдаёт фильмы постранично
+type pages struct {
+	name  string
+	fetch func(ctx context.Context, page int) ([]kinopoisk.Film, int, error)
+}
+
+func (p pages) Name() string { return p.name }
+func (p pages) Paged() bool  { return true }
+
+func (p pages) Units(totalPages int) []int64 {
+	units := make([]int64, 0, totalPages)
+	for i := 1; i <= totalPages; i++ {
+		units = append(units, int64(i))
+	}
+	return units
+}
+
+func (p pages) Fetch(ctx context.Context, unit int64) ([]kinopoisk.Film, int, error) {
+	return p.fetch(ctx, int(unit))
+}
+
+// Collection — подборка Kinopoisk (TOP_POPULAR_ALL, TOP_250_MOVIES, …)
+func Collection(kp Kinopoisk, collection string) Source {
+	return pages{
+		name: "collection:" + collection,
+		fetch: func(ctx context.Context, page int) ([]kinopoisk.Film, int, error) {
+			return kp.GetCollection(ctx, collection, page)
+		},
+	}
+}
+
+// Keyword — результаты поиска Kinopoisk по ключевому слову
+func Keyword(kp Kinopoisk, keyword string) Source {
+	return pages{
+		name: "keyword:" + keyword,
+		fetch: func(ctx context.Context, page int) ([]kinopoisk.Film, int, error) {
+			return kp.SearchByKeyword(ctx, keyword, page)
+		},
+	}
+}
+
+// idList — источник из списка Kinopoisk ID; единица работы — один фильм
+type idList struct {
+	kp   Kinopoisk
+	name string
+	ids  []int64
+}
+
+// IDs — фильмы по списку Kinopoisk ID; name отличает списки друг от друга
+func IDs(kp Kinopoisk, name string, ids []int64) Source {
+	return idList{kp: kp, name: "ids:" + name, ids: ids}
+}
+
+func (l idList) Name() string      { return l.name }
+func (l idList) Paged() bool       { return false }
+func (l idList) Units(int) []int64 { return l.ids }
+
+func (l idList) Fetch(ctx context.Context, unit int64) ([]kinopoisk.Film, int, error) {
+	f, err := l.kp.GetFilm(ctx, unit)
+	if err != nil {
+		return nil, 0, err
+	}
+	f.KinopoiskID = unit
+	return []kinopoisk.Film{*f}, 0, nil
+}
+
+// ReadIDs читает Kinopoisk ID по одному в строке; пустые строки и
+// комментарии после # пропускаются, повторы отбрасываются
+func ReadIDs(r io.Reader) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s, _, _ := strings.Cut(sc.Text(), "#")
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("line %d: invalid film id %q", line, s)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, sc.Err()
+}
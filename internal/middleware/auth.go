@@ -4,34 +4,116 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/AlexKeyyyy/movies-picker/internal/problem"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type key string
 
-const UserIDKey key = "UserID"
+const (
+	UserIDKey key = "UserID"
+	RoleKey   key = "Role"
+	TokenKey  key = "Token"
+)
+
+// Token — сведения об access-токене текущего запроса
+type Token struct {
+	ID        string // jti
+	SessionID string // sid — сессия входа, к которой привязан токен
+	ExpiresAt time.Time
+}
 
-func JWT(secret string) func(http.Handler) http.Handler {
+// RevocationChecker проверяет, не отозван ли access-токен
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, jti, sessionID string) (bool, error)
+}
+
+// JWT проверяет подпись и срок действия access-токена, а если передан
+// revoked — ещё и то, что токен не отозван (logout, ротация с повтором)
+func JWT(secret string, revoked RevocationChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
 			if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
-				http.Error(w, "Missing or invalid token", http.StatusUnauthorized)
+				problem.Error(w, r, "Missing or invalid token", http.StatusUnauthorized)
 				return
 			}
 			tokenStr := strings.TrimPrefix(auth, "Bearer ")
 			token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
 				return []byte(secret), nil
-			})
+			}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 			if err != nil || !token.Valid {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				problem.Error(w, r, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 			claims := token.Claims.(jwt.MapClaims)
-			userID := int64(claims["user_id"].(float64))
-			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			rawID, ok := claims["user_id"].(float64)
+			if !ok {
+				problem.Error(w, r, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			role, _ := claims["role"].(string)
+			if role == "" {
+				role = "user"
+			}
+
+			// токены, выпущенные до появления jti, действуют до своего exp
+			tok := Token{}
+			tok.ID, _ = claims["jti"].(string)
+			tok.SessionID, _ = claims["sid"].(string)
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				tok.ExpiresAt = exp.Time
+			}
+			if revoked != nil && tok.ID != "" {
+				isRevoked, err := revoked.IsTokenRevoked(r.Context(), tok.ID, tok.SessionID)
+				if err != nil {
+					problem.Error(w, r, "cannot verify token", http.StatusInternalServerError)
+					return
+				}
+				if isRevoked {
+					problem.Error(w, r, "Token revoked", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, int64(rawID))
+			ctx = context.WithValue(ctx, RoleKey, role)
+			ctx = context.WithValue(ctx, TokenKey, tok)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// RequireRole пропускает запрос, только если роль из токена входит в roles.
+// Должен стоять после JWT.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasRole(r, roles) {
+				problem.Error(w, r, "insufficient role", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RoleOverride разрешает пользователям с ролями roles доступ к чужим данным в PathUser
+func RoleOverride(roles ...string) OverrideFunc {
+	return func(r *http.Request, actorID, targetID int64) bool {
+		return hasRole(r, roles)
+	}
+}
+
+func hasRole(r *http.Request, roles []string) bool {
+	role, _ := r.Context().Value(RoleKey).(string)
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package middleware_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,7 +14,7 @@ import (
 
 func TestJWT(t *testing.T) {
 	secret := "test-secret"
-	middlewareFunc := middleware.JWT(secret)
+	middlewareFunc := middleware.JWT(secret, nil)
 
 	tests := []struct {
 		name           string
@@ -101,3 +102,109 @@ func TestJWT(t *testing.T) {
 		})
 	}
 }
+
+type fakeRevocations struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f fakeRevocations) IsTokenRevoked(ctx context.Context, jti, sessionID string) (bool, error) {
+	return f.revoked[jti] || f.revoked[sessionID], f.err
+}
+
+func TestJWT_Revocation(t *testing.T) {
+	secret := "test-secret"
+	sign := func(claims jwt.MapClaims) string {
+		claims["user_id"] = float64(7)
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return s
+	}
+
+	tests := []struct {
+		name           string
+		checker        fakeRevocations
+		token          string
+		expectedStatus int
+	}{
+		{
+			name:           "Active token",
+			checker:        fakeRevocations{revoked: map[string]bool{}},
+			token:          sign(jwt.MapClaims{"jti": "a1", "sid": "s1"}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Denylisted jti",
+			checker:        fakeRevocations{revoked: map[string]bool{"a1": true}},
+			token:          sign(jwt.MapClaims{"jti": "a1", "sid": "s1"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Revoked session",
+			checker:        fakeRevocations{revoked: map[string]bool{"s1": true}},
+			token:          sign(jwt.MapClaims{"jti": "a2", "sid": "s1"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Checker failure",
+			checker:        fakeRevocations{err: assert.AnError},
+			token:          sign(jwt.MapClaims{"jti": "a1", "sid": "s1"}),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.JWT(secret, tt.checker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tok := r.Context().Value(middleware.TokenKey).(middleware.Token)
+				assert.Equal(t, "a1", tok.ID)
+				assert.Equal(t, "s1", tok.SessionID)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("POST", "/auth/logout", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	secret := "test-secret"
+	tests := []struct {
+		name           string
+		role           interface{}
+		expectedStatus int
+	}{
+		{name: "Admin allowed", role: "admin", expectedStatus: http.StatusOK},
+		{name: "Moderator allowed", role: "moderator", expectedStatus: http.StatusOK},
+		{name: "User forbidden", role: "user", expectedStatus: http.StatusForbidden},
+		{name: "Token without role", role: nil, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{
+				"user_id": float64(1),
+				"exp":     time.Now().Add(time.Hour).Unix(),
+			}
+			if tt.role != nil {
+				claims["role"] = tt.role
+			}
+			tokenStr, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			handler := middleware.JWT(secret, nil)(middleware.RequireRole("admin", "moderator")(ok))
+
+			req := httptest.NewRequest("GET", "/admin/movies", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenStr)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+// TargetUserIDKey — ID пользователя из пути, над данными которого выполняется запрос
+const TargetUserIDKey key = "TargetUserID"
+
+// OverrideFunc решает, может ли actorID работать с данными targetID
+// (например, администратор); nil — доступ только к своим данным
+type OverrideFunc func(r *http.Request, actorID, targetID int64) bool
+
+// PathUser разрешает параметр пути param ("me" — текущий пользователь) и
+// пропускает запрос, только если это сам пользователь или override разрешает
+// доступ. Должен стоять после JWT; handlers берут ID из TargetUserIDKey.
+func PathUser(param string, override OverrideFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actorID, ok := r.Context().Value(UserIDKey).(int64)
+			if !ok {
+				problem.Error(w, r, "Missing or invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			targetID := actorID
+			if raw := chi.URLParam(r, param); raw != "me" {
+				id, err := strconv.ParseInt(raw, 10, 64)
+				if err != nil || id <= 0 {
+					problem.Error(w, r, "invalid user id", http.StatusBadRequest)
+					return
+				}
+				targetID = id
+			}
+			if targetID != actorID && (override == nil || !override(r, actorID, targetID)) {
+				problem.Error(w, r, "access to another user's data is forbidden", http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), TargetUserIDKey, targetID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathUser(t *testing.T) {
+	// пользователь 1 — «администратор», которому разрешён доступ к чужим данным
+	adminOverride := func(r *http.Request, actorID, targetID int64) bool { return actorID == 1 }
+
+	tests := []struct {
+		name           string
+		override       middleware.OverrideFunc
+		actorID        int64
+		path           string
+		expectedStatus int
+		expectedTarget int64
+	}{
+		{name: "Own ID", actorID: 5, path: "/users/5/ratings", expectedStatus: http.StatusOK, expectedTarget: 5},
+		{name: "Alias me", actorID: 5, path: "/users/me/ratings", expectedStatus: http.StatusOK, expectedTarget: 5},
+		{name: "Another user", actorID: 5, path: "/users/6/ratings", expectedStatus: http.StatusForbidden},
+		{name: "Invalid ID", actorID: 5, path: "/users/abc/ratings", expectedStatus: http.StatusBadRequest},
+		{
+			name:           "Override allows",
+			override:       adminOverride,
+			actorID:        1,
+			path:           "/users/6/ratings",
+			expectedStatus: http.StatusOK,
+			expectedTarget: 6,
+		},
+		{
+			name:           "Override denies",
+			override:       adminOverride,
+			actorID:        2,
+			path:           "/users/6/ratings",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Route("/users/{userID}", func(r chi.Router) {
+				r.Use(middleware.PathUser("userID", tt.override))
+				r.Get("/ratings", func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, tt.expectedTarget, r.Context().Value(middleware.TargetUserIDKey).(int64))
+					w.WriteHeader(http.StatusOK)
+				})
+			})
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.actorID))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
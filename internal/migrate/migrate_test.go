@@ -0,0 +1,178 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/db/migrations"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFS = fstest.MapFS{
+	"0002_add_tags.up.sql":   {Data: []byte("CREATE TABLE tags (id INT);")},
+	"0002_add_tags.down.sql": {Data: []byte("DROP TABLE tags;")},
+	"0001_initial.up.sql":    {Data: []byte("CREATE TABLE users (id INT);")},
+	"0001_initial.down.sql":  {Data: []byte("DROP TABLE users;")},
+	"README.md":              {Data: []byte("not a migration")},
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{name: "Sorted by version", fsys: testFS, versions: []int64{1, 2}},
+		{
+			name:    "Bad file name",
+			fsys:    fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: true,
+		},
+		{
+			name:    "Down without up",
+			fsys:    fstest.MapFS{"0001_initial.down.sql": {Data: []byte("DROP TABLE users;")}},
+			wantErr: true,
+		},
+		{
+			name: "Conflicting names",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql": {Data: []byte("SELECT 1;")},
+				"0001_b.up.sql": {Data: []byte("SELECT 2;")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := Load(tt.fsys)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var versions []int64
+			for _, m := range list {
+				versions = append(versions, m.Version)
+			}
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := Load(migrations.FS)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, list)
+	for i, m := range list {
+		assert.Equal(t, int64(i+1), m.Version, "versions must be sequential")
+		assert.NotEmpty(t, m.Down, "version %d has no down migration", m.Version)
+	}
+}
+
+func newMockMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	mockDB, mock, _ := sqlmock.New()
+	t.Cleanup(func() { mockDB.Close() })
+	m, err := New(sqlx.NewDb(mockDB, "sqlmock"), testFS)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	return m, mock
+}
+
+func TestUp(t *testing.T) {
+	t.Run("Applies pending only", func(t *testing.T) {
+		m, mock := newMockMigrator(t)
+		mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec(`CREATE TABLE tags`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO schema_migrations \(version, name\) VALUES \(\$1, \$2\)`).
+			WithArgs(int64(2), "add_tags").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockKey).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		applied, err := m.Up()
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, "add_tags", applied[0].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed migration is rolled back", func(t *testing.T) {
+		m, mock := newMockMigrator(t)
+		mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(`CREATE TABLE users`).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockKey).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		applied, err := m.Up()
+		assert.Error(t, err)
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDown(t *testing.T) {
+	m, mock := newMockMigrator(t)
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+			AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE tags`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reverted, err := m.Down(1)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, 1)
+	assert.Equal(t, int64(2), reverted[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus(t *testing.T) {
+	m, mock := newMockMigrator(t)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, at))
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	list, err := m.Status()
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, at, *list[0].AppliedAt)
+	assert.Nil(t, list[1].AppliedAt)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_initial.up.sql"), []byte("SELECT 1;"), 0o644))
+
+	up, down, err := Create(dir, "Add Movie Tags")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "0002_add_movie_tags.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "0002_add_movie_tags.down.sql"), down)
+
+	_, _, err = Create(dir, "drop;table")
+	assert.Error(t, err)
+}
@@ -1,12 +1,87 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+)
 
 type User struct {
-	ID           int64  `db:"user_id" json:"user_id"`
-	Email        string `db:"email" json:"email"`
-	PasswordHash string `db:"password_hash"`
-	CreatedAt    string `db:"created_at" json:"created_at"`
+	ID           int64      `db:"user_id" json:"user_id"`
+	Email        string     `db:"email" json:"email"`
+	PasswordHash string     `db:"password_hash" json:"-"`
+	CreatedAt    string     `db:"created_at" json:"created_at"`
+	Role         string     `db:"role" json:"role"`
+	DisabledAt   *time.Time `db:"disabled_at" json:"disabled_at,omitempty"`
+	VerifiedAt   *time.Time `db:"email_verified_at" json:"email_verified_at,omitempty"`
+}
+
+// Назначение одноразовых токенов из писем
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+// UserToken — погашенный одноразовый токен
+type UserToken struct {
+	UserID int64  `db:"user_id"`
+	Email  string `db:"email"`
+}
+
+// Роли пользователей
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// IsValidRole сообщает, известна ли роль
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleModerator || role == RoleAdmin
+}
+
+// UserList — страница списка пользователей для администратора
+type UserList struct {
+	Items      []User `json:"items"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+}
+
+// UserUpdate — изменения пользователя администратором; nil — не менять
+type UserUpdate struct {
+	Role     *string `json:"role"`
+	Disabled *bool   `json:"disabled"`
+}
+
+func (u *UserUpdate) Validate() error {
+	var v validation.Validator
+	v.Check(u.Role != nil || u.Disabled != nil, "role", "role or disabled is required")
+	if u.Role != nil {
+		v.OneOf("role", *u.Role, RoleUser, RoleModerator, RoleAdmin)
+	}
+	return v.Err()
+}
+
+// TokenPair — ответ на логин и обновление токенов
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken — запись о refresh-токене вместе с состоянием его сессии
+type RefreshToken struct {
+	TokenHash      string `db:"token_hash"`
+	SessionID      string `db:"session_id"`
+	UserID         int64  `db:"user_id"`
+	Expired        bool   `db:"expired"`
+	Used           bool   `db:"used"`
+	SessionRevoked bool   `db:"session_revoked"`
+	Role           string `db:"role"`
+	UserDisabled   bool   `db:"user_disabled"`
 }
 
 type Movie struct {
@@ -17,6 +92,46 @@ type Movie struct {
 	Description     string    `db:"description" json:"description"`
 	RatingKinopoisk float64   `db:"rating_kinopoisk" json:"ratingKinopoisk"`
 	LastSync        time.Time `db:"last_sync"   json:"last_sync"`
+	TitleEn         string    `db:"title_en"       json:"title_en"`
+	OriginalTitle   string    `db:"original_title" json:"original_title"`
+	Runtime         int       `db:"runtime"        json:"runtime"` // минуты
+	AgeRating       string    `db:"age_rating"     json:"age_rating"`
+	RatingImdb      float64   `db:"rating_imdb"    json:"ratingImdb"`
+	Type            string    `db:"film_type"      json:"type"` // FILM, TV_SERIES, ...
+	Genres          []string  `db:"-"              json:"genres,omitempty"`
+	Countries       []string  `db:"-"              json:"countries,omitempty"`
+}
+
+// MoviePatch — ручная правка карточки фильма; nil — не менять
+type MoviePatch struct {
+	Title         *string `json:"title"`
+	TitleEn       *string `json:"title_en"`
+	OriginalTitle *string `json:"original_title"`
+	Year          *int    `json:"year"`
+	Description   *string `json:"description"`
+	PosterURL     *string `json:"poster_url"`
+	Runtime       *int    `json:"runtime"`
+}
+
+func (p *MoviePatch) Validate() error {
+	var v validation.Validator
+	if p.Title != nil {
+		v.Required("title", *p.Title)
+		v.MaxLength("title", *p.Title, 255)
+	}
+	if p.TitleEn != nil {
+		v.MaxLength("title_en", *p.TitleEn, 255)
+	}
+	if p.OriginalTitle != nil {
+		v.MaxLength("original_title", *p.OriginalTitle, 255)
+	}
+	if p.Year != nil {
+		v.Range("year", *p.Year, 1888, 2100)
+	}
+	if p.Runtime != nil {
+		v.Range("runtime", *p.Runtime, 0, 1440)
+	}
+	return v.Err()
 }
 
 type WatchlistItem struct {
@@ -34,10 +149,314 @@ type RatingItem struct {
 	RatedAt string `db:"rated_at" json:"rated_at"`
 }
 
-type ReviewItem struct {
-	VideoID      string `json:"video_id"`
-	VideoURL     string `json:"video_url"`
-	Title        string `json:"title"`
-	ChannelTitle string `json:"channel_title"`
-	ThumbnailURL string `json:"thumbnail_url"`
+// Виды видео о фильме (Video.Kind)
+const (
+	VideoTrailer = "trailer"
+	VideoTeaser  = "teaser"
+	VideoReview  = "review"
+)
+
+// Источники видео (Video.Source)
+const (
+	SourceYouTube   = "youtube"
+	SourceKinopoisk = "kinopoisk" // плеер Kinopoisk или YouTube-ролик из карточки Kinopoisk
+)
+
+// Video — трейлер, тизер или обзор фильма
+type Video struct {
+	VideoID      string     `db:"video_id"      json:"video_id"`
+	VideoURL     string     `db:"video_url"     json:"video_url"`
+	Title        string     `db:"title"         json:"title"`
+	ChannelTitle string     `db:"channel_title" json:"channel_title"`
+	ThumbnailURL string     `db:"thumbnail_url" json:"thumbnail_url"`
+	Kind         string     `db:"kind"          json:"kind"`
+	Source       string     `db:"source"        json:"source"`
+	Language     string     `db:"language"      json:"language,omitempty"` // ISO 639-1, если известен
+	Duration     int        `db:"duration"      json:"duration,omitempty"` // секунды
+	PublishedAt  *time.Time `db:"published_at"  json:"published_at,omitempty"`
+}
+
+// Источники рецензий (Review.Source)
+const (
+	ReviewSourceYouTube   = "youtube"
+	ReviewSourceKinopoisk = "kinopoisk"
+	ReviewSourceUsers     = "users" // рецензии пользователей сервиса
+)
+
+// Тональность рецензии (Review.Sentiment)
+const (
+	SentimentPositive = "positive"
+	SentimentNegative = "negative"
+	SentimentNeutral  = "neutral"
+)
+
+// Review — рецензия на фильм из одного из источников: текст или видеообзор
+type Review struct {
+	Source      string     `json:"source"`
+	ID          string     `json:"id"` // уникален в пределах источника
+	Title       string     `json:"title,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	UserID      int64      `json:"user_id,omitempty"` // автор — пользователь сервиса
+	Text        string     `json:"text,omitempty"`
+	Sentiment   string     `json:"sentiment,omitempty"` // positive, negative или neutral
+	Rating      int        `json:"rating,omitempty"`    // оценка автора, 1–10
+	Helpful     int        `json:"helpful,omitempty"`   // сколько читателей сочли рецензию полезной
+	Spoiler     bool       `json:"spoiler,omitempty"`   // текст раскрывает сюжет
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Video       *Video     `json:"video,omitempty"` // у видеообзоров
+}
+
+// ReviewSourceStatus — чем закончился запрос к источнику рецензий
+type ReviewSourceStatus struct {
+	Source string `json:"source"`
+	Status string `json:"status"` // ok, timeout или unavailable
+	Count  int    `json:"count"`
+}
+
+// ReviewList — рецензии на фильм из всех источников
+type ReviewList struct {
+	Items   []Review             `json:"items"`
+	Sources []ReviewSourceStatus `json:"sources"`
+}
+
+// Статусы рецензий пользователей (UserReview.Status)
+const (
+	ReviewPublished = "published"
+	ReviewHidden    = "hidden" // скрыта модератором
+)
+
+// UserReview — рецензия пользователя, привязанная к его оценке фильма
+type UserReview struct {
+	UserID     int64     `db:"user_id"        json:"user_id"`
+	MovieID    int64     `db:"movie_id"       json:"movie_id"`
+	Rating     int       `db:"rating"         json:"rating"`
+	Title      string    `db:"review_title"   json:"title"`
+	Text       string    `db:"review_text"    json:"text"`
+	Spoiler    bool      `db:"review_spoiler" json:"spoiler"`
+	Status     string    `db:"review_status"  json:"status"`
+	Helpful    int       `db:"helpful"        json:"helpful"`   // голоса «полезно»
+	Unhelpful  int       `db:"unhelpful"      json:"unhelpful"` // голоса «бесполезно»
+	ReviewedAt time.Time `db:"reviewed_at"    json:"reviewed_at"`
+}
+
+// UserReviewList — страница рецензий пользователей на фильм
+type UserReviewList struct {
+	Items      []UserReview `json:"items"`
+	Page       int          `json:"page"`
+	Size       int          `json:"size"`
+	Total      int          `json:"total"`
+	TotalPages int          `json:"total_pages"`
+}
+
+// ReportedReview — рецензия в очереди модерации с открытыми жалобами
+type ReportedReview struct {
+	UserReview
+	Reports        int        `db:"reports"          json:"reports"`
+	Reasons        []string   `db:"-"                json:"reasons"` // непустые причины жалоб
+	LastReportedAt *time.Time `db:"last_reported_at" json:"last_reported_at,omitempty"`
+}
+
+// ReportedReviewList — страница очереди модерации рецензий
+type ReportedReviewList struct {
+	Items      []ReportedReview `json:"items"`
+	Page       int              `json:"page"`
+	Size       int              `json:"size"`
+	Total      int              `json:"total"`
+	TotalPages int              `json:"total_pages"`
+}
+
+// Recommendation — фильм из персональной подборки с оценкой и пояснением
+type Recommendation struct {
+	Movie
+	Score          float64 `json:"score"`
+	BecauseMovieID int64   `json:"because_movie_id,omitempty"`
+	Explanation    string  `json:"explanation,omitempty"`
+}
+
+// PickFilter — ограничения для выбора фильма на вечер
+type PickFilter struct {
+	YearFrom   int
+	YearTo     int
+	MinRating  float64
+	Genre      string
+	MaxRuntime int // минуты
+}
+
+// PickCandidate — фильм-кандидат для выбора; AddedAt заполнен для фильмов из «Смотреть позже»
+type PickCandidate struct {
+	Movie
+	AddedAt *time.Time `db:"added_at" json:"added_at,omitempty"`
+}
+
+// PickResult — выбранный фильм и источник, из которого он выбран
+type PickResult struct {
+	PickCandidate
+	Source     string `json:"source"` // watchlist | catalog
+	Candidates int    `json:"candidates"`
+}
+
+// Session — совместный выбор фильма несколькими пользователями
+type Session struct {
+	ID        int64           `db:"session_id" json:"session_id"`
+	OwnerID   int64           `db:"owner_id"   json:"owner_id"`
+	Title     string          `db:"title"      json:"title"`
+	CreatedAt string          `db:"created_at" json:"created_at"`
+	Members   []SessionMember `db:"-"          json:"members,omitempty"`
+}
+
+type SessionMember struct {
+	SessionID int64      `db:"session_id" json:"-"`
+	UserID    int64      `db:"user_id"    json:"user_id"`
+	Email     string     `db:"email"      json:"email"`
+	Status    string     `db:"status"     json:"status"` // invited | joined
+	JoinedAt  *time.Time `db:"joined_at"  json:"joined_at,omitempty"`
+}
+
+type SessionVote struct {
+	SessionID int64  `db:"session_id" json:"-"`
+	UserID    int64  `db:"user_id"    json:"user_id"`
+	MovieID   int64  `db:"movie_id"   json:"movie_id"`
+	Kind      string `db:"kind"       json:"kind"` // up | down | veto
+}
+
+// SessionCandidate — фильм-кандидат для совместного просмотра
+type SessionCandidate struct {
+	Movie
+	Score           float64 `json:"score"`
+	PredictedRating float64 `json:"predicted_rating"`
+	MinPredicted    float64 `json:"min_predicted"`
+	InWatchlists    int     `json:"in_watchlists"`
+	Upvotes         int     `json:"upvotes"`
+	Downvotes       int     `json:"downvotes"`
+}
+
+// SessionResult — итог совместного выбора
+type SessionResult struct {
+	SessionID  int64              `json:"session_id"`
+	Members    int                `json:"members"`
+	Candidates []SessionCandidate `json:"candidates"`
+	Vetoed     []int64            `json:"vetoed"`
+}
+
+// MovieFilter — фильтры и сортировка каталога фильмов
+type MovieFilter struct {
+	YearFrom   int
+	YearTo     int
+	RatingFrom float64
+	RatingTo   float64
+	Genre      string
+	Country    string
+	Type       string
+	PersonID   int64  // фильмы с участием человека
+	PersonName string // фильмы с участием людей, чьё имя содержит строку
+	Sort       string // title | year | rating | last_sync
+	Order      string // asc | desc
+}
+
+// FacetCount — число фильмов для одного значения фасета
+type FacetCount struct {
+	Value string `db:"value" json:"value"`
+	Count int    `db:"count" json:"count"`
+}
+
+// MovieFacets — распределение отфильтрованного каталога по жанрам и десятилетиям
+type MovieFacets struct {
+	Genres  []FacetCount `json:"genres"`
+	Decades []FacetCount `json:"decades"`
+}
+
+// MovieList — страница списка фильмов; общий формат для каталога, поиска и топа
+type MovieList struct {
+	Items      []Movie      `json:"items"`
+	Page       int          `json:"page"`
+	Size       int          `json:"size"`
+	Total      int          `json:"total"`
+	TotalPages int          `json:"total_pages"`
+	Source     string       `json:"source"` // local | remote
+	Facets     *MovieFacets `json:"facets,omitempty"`
+}
+
+// SyncRun — итоги одного прохода фонового обновления каталога
+type SyncRun struct {
+	Batch           int  `json:"batch"`   // сколько устаревших фильмов выбрано
+	Checked         int  `json:"checked"` // сколько из них уже обработано
+	Updated         int  `json:"updated"`
+	Missing         int  `json:"missing"` // Kinopoisk больше не отдаёт фильм
+	Failed          int  `json:"failed"`
+	BudgetExhausted bool `json:"budget_exhausted"`
+}
+
+// SyncStatus — состояние фонового обновления каталога
+type SyncStatus struct {
+	Enabled     bool       `json:"enabled"`
+	Running     bool       `json:"running"`
+	Interval    string     `json:"interval"`
+	TTL         string     `json:"ttl"`
+	StaleMovies int        `json:"stale_movies"` // сейчас ждут обновления
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	NextRunAt   *time.Time `json:"next_run_at,omitempty"`
+	LastRun     SyncRun    `json:"last_run"`     // текущий или последний проход
+	Totals      SyncRun    `json:"totals"`       // с момента запуска сервера
+	BudgetUsed  int        `json:"budget_used"`  // запросов к Kinopoisk за сутки
+	BudgetLimit int        `json:"budget_limit"` // после него обновление ждёт следующих суток
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// --- Импорт каталога ---
+
+// ImportJob — чекпоинт импорта одного источника (подборки, поиска, списка ID)
+type ImportJob struct {
+	Source     string     `db:"source"`
+	TotalPages int        `db:"total_pages"` // 0 — ещё неизвестно или источник без страниц
+	StartedAt  time.Time  `db:"started_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+// --- Люди ---
+
+// Person — актёр или участник съёмочной группы; ID — staffId в Kinopoisk
+type Person struct {
+	ID          int64  `db:"person_id"    json:"person_id"`
+	Name        string `db:"name"         json:"name"`
+	NameEn      string `db:"name_en"      json:"name_en,omitempty"`
+	PhotoURL    string `db:"photo_url"    json:"photo_url,omitempty"`
+	MoviesCount int    `db:"movies_count" json:"movies_count"`
+}
+
+// PersonList — страница результатов поиска людей
+type PersonList struct {
+	Items      []Person `json:"items"`
+	Page       int      `json:"page"`
+	Size       int      `json:"size"`
+	Total      int      `json:"total"`
+	TotalPages int      `json:"total_pages"`
+}
+
+// PersonMovie — фильм в фильмографии человека
+type PersonMovie struct {
+	MovieID    int64   `db:"movie_id"         json:"movie_id"`
+	Title      string  `db:"title"            json:"title"`
+	Year       int     `db:"year"             json:"year"`
+	PosterURL  string  `db:"poster_url"       json:"poster_url"`
+	Rating     float64 `db:"rating_kinopoisk" json:"ratingKinopoisk"`
+	Profession string  `db:"profession"       json:"profession"`
+	Role       string  `db:"role"             json:"role,omitempty"`
+}
+
+// PersonDetails — человек и его фильмография в каталоге
+type PersonDetails struct {
+	Person
+	Movies []PersonMovie `json:"movies"`
+}
+
+// CastMember — участие человека в фильме
+type CastMember struct {
+	PersonID   int64  `db:"person_id"  json:"person_id"`
+	Name       string `db:"name"       json:"name"`
+	NameEn     string `db:"name_en"    json:"name_en,omitempty"`
+	PhotoURL   string `db:"photo_url"  json:"photo_url,omitempty"`
+	Profession string `db:"profession" json:"profession"` // DIRECTOR, ACTOR, ...
+	Role       string `db:"role"       json:"role,omitempty"`
 }
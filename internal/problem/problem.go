@@ -0,0 +1,63 @@
+// Package problem отдаёт ошибки API в формате RFC 7807 (application/problem+json).
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem — тело ответа с ошибкой
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Errors   validation.Errors `json:"errors,omitempty"` // ошибки по полям
+}
+
+// New создаёт Problem без собственного типа: смысл ошибки задаёт статус
+func New(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Write отправляет p; instance — путь запроса
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Error — замена http.Error с ответом в формате problem+json
+func Error(w http.ResponseWriter, r *http.Request, detail string, status int) {
+	Write(w, r, New(status, detail))
+}
+
+// Validation отвечает 400 со списком ошибок по полям
+func Validation(w http.ResponseWriter, r *http.Request, errs validation.Errors) {
+	p := New(http.StatusBadRequest, "request validation failed")
+	p.Errors = errs
+	Write(w, r, p)
+}
+
+// NotFound и MethodNotAllowed подходят для chi.Router.NotFound/MethodNotAllowed
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, "route not found", http.StatusNotFound)
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, "method not allowed", http.StatusMethodNotAllowed)
+}
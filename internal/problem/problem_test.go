@@ -0,0 +1,31 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	req := httptest.NewRequest("POST", "/users/me/ratings", nil)
+	rr := httptest.NewRecorder()
+
+	Validation(rr, req, validation.Errors{{Field: "rating", Message: "must be between 1 and 10"}})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	var p Problem
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&p))
+	assert.Equal(t, Problem{
+		Type:     "about:blank",
+		Title:    "Bad Request",
+		Status:   http.StatusBadRequest,
+		Detail:   "request validation failed",
+		Instance: "/users/me/ratings",
+		Errors:   validation.Errors{{Field: "rating", Message: "must be between 1 and 10"}},
+	}, p)
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// --- Admin: users ---
+
+// ListUsers возвращает страницу пользователей в порядке регистрации
+func (r *Repo) ListUsers(ctx context.Context, offset, limit int) ([]models.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var users []models.User
+	err := r.db.SelectContext(ctx, &users, `
+		SELECT user_id, email, created_at, role, disabled_at, email_verified_at
+		FROM users ORDER BY user_id LIMIT $1 OFFSET $2`, limit, offset)
+	return users, err
+}
+
+// CountUsers возвращает общее число пользователей
+func (r *Repo) CountUsers(ctx context.Context) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var total int
+	err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM users`)
+	return total, err
+}
+
+// SetUserRole меняет роль пользователя; sql.ErrNoRows, если пользователя нет
+func (r *Repo) SetUserRole(ctx context.Context, userID int64, role string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE user_id = $2`, role, userID)
+	return expectAffected(res, err)
+}
+
+// SetUserDisabled блокирует или разблокирует пользователя
+func (r *Repo) SetUserDisabled(ctx context.Context, userID int64, disabled bool) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, CURRENT_TIMESTAMP) END
+		WHERE user_id = $2`, disabled, userID)
+	return expectAffected(res, err)
+}
+
+// SetRoleByEmails выдаёт роль пользователям с указанными email
+func (r *Repo) SetRoleByEmails(ctx context.Context, emails []string, role string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if len(emails) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE email = ANY($2)`, role, pq.Array(emails))
+	return err
+}
+
+// --- Admin: movies ---
+
+// UpdateMovieFields правит описательные поля фильма; nil-поля не меняются.
+// Исправленные поля запоминаются в edited_fields, и UpsertMovie их не затирает.
+func (r *Repo) UpdateMovieFields(ctx context.Context, id int64, p models.MoviePatch) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	b := &whereBuilder{}
+	var sets, cols []string
+	set := func(col string, v interface{}) {
+		sets = append(sets, col+" = "+b.arg(v))
+		cols = append(cols, col)
+	}
+	if p.Title != nil {
+		set("title", *p.Title)
+	}
+	if p.TitleEn != nil {
+		set("title_en", *p.TitleEn)
+	}
+	if p.OriginalTitle != nil {
+		set("original_title", *p.OriginalTitle)
+	}
+	if p.Year != nil {
+		set("year", *p.Year)
+	}
+	if p.Description != nil {
+		set("description", *p.Description)
+	}
+	if p.PosterURL != nil {
+		set("poster_url", *p.PosterURL)
+	}
+	if p.Runtime != nil {
+		set("runtime", *p.Runtime)
+	}
+	if len(sets) == 0 {
+		exists, err := r.MovieExists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+		return nil
+	}
+	sets = append(sets, fmt.Sprintf(
+		"edited_fields = ARRAY(SELECT DISTINCT unnest(edited_fields || %s::text[]))", b.arg(pq.Array(cols))))
+	query := fmt.Sprintf("UPDATE movies SET %s WHERE movie_id = %s", strings.Join(sets, ", "), b.arg(id))
+	res, err := r.db.ExecContext(ctx, query, b.args...)
+	return expectAffected(res, err)
+}
+
+// DeleteMovie удаляет фильм вместе со всеми ссылками на него и запоминает
+// его ID в removed_movies, чтобы фильм не загрузился из Kinopoisk заново
+func (r *Repo) DeleteMovie(ctx context.Context, id int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM movies WHERE movie_id = $1`, id)
+	if err := expectAffected(res, err); err != nil {
+		return err
+	}
+	if err := removeMovie(ctx, tx, id, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// removeMovie записывает ID удалённого фильма в removed_movies; mergedInto —
+// фильм, на который ведёт ID слитого дубля, nil — фильм удалён
+func removeMovie(ctx context.Context, tx *sqlx.Tx, id int64, mergedInto *int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO removed_movies (movie_id, merged_into) VALUES ($1, $2)
+		ON CONFLICT (movie_id) DO UPDATE SET merged_into = EXCLUDED.merged_into, removed_at = NOW()`,
+		id, mergedInto)
+	return err
+}
+
+// GetRemovedMovie сообщает, что модератор удалил фильм id: mergedInto — ID
+// целевого фильма, если id был слит с ним, nil — фильм удалён.
+// sql.ErrNoRows, если фильм не удалялся.
+func (r *Repo) GetRemovedMovie(ctx context.Context, id int64) (mergedInto *int64, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err = r.db.GetContext(ctx, &mergedInto,
+		`SELECT merged_into FROM removed_movies WHERE movie_id = $1`, id)
+	return mergedInto, err
+}
+
+// movieRefs — таблицы, ссылающиеся на фильм, и их колонки без movie_id.
+// При слиянии дублей записи переносятся на целевой фильм; при конфликте
+// остаётся запись целевого фильма, а голоса и жалобы на рецензию
+// переносятся, только если переносится сама оценка с рецензией.
+var movieRefs = []struct {
+	table string
+	cols  string
+}{
+	{"watchlist", "user_id, added_at"},
+	{"ratings", "user_id, rating, rated_at, review_title, review_text, reviewed_at, review_spoiler, review_status"},
+	{"review_votes", "voter_id, author_id, helpful, voted_at"},
+	{"review_reports", "reporter_id, author_id, reason, reported_at, resolved_at"},
+	{"hidden_reviews", "user_id, hidden_at"},
+	{"session_votes", "session_id, user_id, kind, voted_at"},
+	{"movie_genres", "genre_id"},
+	{"movie_countries", "country_id"},
+	{"movie_people", "person_id, profession, role, position"},
+}
+
+// MergeMovies переносит оценки, списки и голоса с дубля sourceID на targetID
+// и удаляет дубль; его ID, как и ID слитых с ним раньше дублей, дальше ведёт
+// на targetID
+func (r *Repo) MergeMovies(ctx context.Context, sourceID, targetID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var found int
+	if err := tx.GetContext(ctx, &found,
+		`SELECT COUNT(*) FROM movies WHERE movie_id IN ($1, $2)`, sourceID, targetID); err != nil {
+		return err
+	}
+	if found != 2 {
+		return sql.ErrNoRows
+	}
+
+	// голоса и жалобы относятся к рецензии дубля: если у автора уже есть оценка
+	// целевого фильма, его рецензия не переносится, и они отбрасываются
+	for _, table := range []string{"review_votes", "review_reports"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`DELETE FROM %s v WHERE v.movie_id = $1
+			   AND EXISTS (SELECT 1 FROM ratings t WHERE t.movie_id = $2 AND t.user_id = v.author_id)`,
+			table), sourceID, targetID); err != nil {
+			return err
+		}
+	}
+	for _, ref := range movieRefs {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`INSERT INTO %[1]s (movie_id, %[2]s) SELECT $2, %[2]s FROM %[1]s WHERE movie_id = $1 ON CONFLICT DO NOTHING`,
+			ref.table, ref.cols), sourceID, targetID); err != nil {
+			return err
+		}
+	}
+	// до удаления дубля: иначе ON DELETE SET NULL оборвёт ссылки на него
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE removed_movies SET merged_into = $2 WHERE merged_into = $1`, sourceID, targetID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM movies WHERE movie_id = $1`, sourceID); err != nil {
+		return err
+	}
+	if err := removeMovie(ctx, tx, sourceID, &targetID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// expectAffected превращает «ни одна строка не изменена» в sql.ErrNoRows
+func expectAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
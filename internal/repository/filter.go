@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// whereBuilder собирает условия WHERE с позиционными плейсхолдерами.
+// Значения пользователя попадают в запрос только через args.
+type whereBuilder struct {
+	conds []string
+	args  []interface{}
+}
+
+// add добавляет условие; каждый «?» в cond заменяется на очередной плейсхолдер $n
+func (b *whereBuilder) add(cond string, args ...interface{}) {
+	for _, a := range args {
+		cond = strings.Replace(cond, "?", b.arg(a), 1)
+	}
+	b.conds = append(b.conds, cond)
+}
+
+// arg добавляет аргумент без условия (например, для LIMIT) и возвращает его плейсхолдер
+func (b *whereBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *whereBuilder) where() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+const (
+	genreCond = `EXISTS (
+            SELECT 1 FROM movie_genres mg JOIN genres g ON g.genre_id = mg.genre_id
+            WHERE mg.movie_id = m.movie_id AND LOWER(g.name) = LOWER(?))`
+	countryCond = `EXISTS (
+            SELECT 1 FROM movie_countries mc JOIN countries c ON c.country_id = mc.country_id
+            WHERE mc.movie_id = m.movie_id AND LOWER(c.name) = LOWER(?))`
+	personIDCond = `EXISTS (
+            SELECT 1 FROM movie_people mp WHERE mp.movie_id = m.movie_id AND mp.person_id = ?)`
+	personNameCond = `EXISTS (
+            SELECT 1 FROM movie_people mp JOIN people p ON p.person_id = mp.person_id
+            WHERE mp.movie_id = m.movie_id AND (p.name ILIKE ? OR p.name_en ILIKE ?))`
+)
+
+// movieSortColumns — допустимые поля сортировки каталога
+var movieSortColumns = map[string]string{
+	"title":     "m.title",
+	"year":      "m.year",
+	"rating":    "m.rating_kinopoisk",
+	"last_sync": "m.last_sync",
+}
+
+// IsValidMovieSort сообщает, поддерживается ли сортировка по полю
+func IsValidMovieSort(field string) bool {
+	_, ok := movieSortColumns[field]
+	return ok
+}
+
+func addPickConditions(b *whereBuilder, f models.PickFilter) {
+	if f.YearFrom > 0 {
+		b.add("m.year >= ?", f.YearFrom)
+	}
+	if f.YearTo > 0 {
+		b.add("m.year <= ?", f.YearTo)
+	}
+	if f.MinRating > 0 {
+		b.add("m.rating_kinopoisk >= ?", f.MinRating)
+	}
+	if f.Genre != "" {
+		b.add(genreCond, f.Genre)
+	}
+	if f.MaxRuntime > 0 {
+		// фильмы с неизвестной длительностью не подходят
+		b.add("m.runtime BETWEEN 1 AND ?", f.MaxRuntime)
+	}
+}
+
+func addMovieConditions(b *whereBuilder, f models.MovieFilter) {
+	if f.YearFrom > 0 {
+		b.add("m.year >= ?", f.YearFrom)
+	}
+	if f.YearTo > 0 {
+		b.add("m.year <= ?", f.YearTo)
+	}
+	if f.RatingFrom > 0 {
+		b.add("m.rating_kinopoisk >= ?", f.RatingFrom)
+	}
+	if f.RatingTo > 0 {
+		b.add("m.rating_kinopoisk <= ?", f.RatingTo)
+	}
+	if f.Genre != "" {
+		b.add(genreCond, f.Genre)
+	}
+	if f.Country != "" {
+		b.add(countryCond, f.Country)
+	}
+	if f.Type != "" {
+		b.add("m.film_type = ?", strings.ToUpper(f.Type))
+	}
+	if f.PersonID > 0 {
+		b.add(personIDCond, f.PersonID)
+	}
+	if f.PersonName != "" {
+		pattern := "%" + escapeLike(f.PersonName) + "%"
+		b.add(personNameCond, pattern, pattern)
+	}
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// movieOrderBy строит ORDER BY по белому списку полей
+func movieOrderBy(f models.MovieFilter) string {
+	col, ok := movieSortColumns[f.Sort]
+	if !ok {
+		col = movieSortColumns["title"]
+	}
+	dir := "ASC"
+	if strings.EqualFold(f.Order, "desc") {
+		dir = "DESC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, m.movie_id", col, dir)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- Import checkpoints ---
+
+// GetImportJob возвращает чекпоинт импорта источника (sql.ErrNoRows, если его нет)
+func (r *Repo) GetImportJob(ctx context.Context, source string) (*models.ImportJob, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var j models.ImportJob
+	err := r.db.GetContext(ctx, &j, `
+		SELECT source, total_pages, started_at, updated_at, finished_at
+		FROM import_jobs WHERE source = $1`, source)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// SaveImportJob создаёт чекпоинт источника или обновляет число страниц;
+// totalPages = 0 не затирает уже известное значение
+func (r *Repo) SaveImportJob(ctx context.Context, source string, totalPages int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO import_jobs (source, total_pages) VALUES ($1, $2)
+		ON CONFLICT (source) DO UPDATE SET
+		  total_pages = COALESCE(NULLIF(EXCLUDED.total_pages, 0), import_jobs.total_pages),
+		  updated_at  = NOW()`, source, totalPages)
+	return err
+}
+
+// FinishImportJob отмечает, что все единицы работы источника загружены
+func (r *Repo) FinishImportJob(ctx context.Context, source string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `UPDATE import_jobs SET finished_at = NOW(), updated_at = NOW() WHERE source = $1`, source)
+	return err
+}
+
+// ResetImportJob удаляет чекпоинт, чтобы импорт начался заново
+func (r *Repo) ResetImportJob(ctx context.Context, source string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `DELETE FROM import_jobs WHERE source = $1`, source)
+	return err
+}
+
+// ListDoneImportUnits возвращает уже загруженные единицы работы источника
+func (r *Repo) ListDoneImportUnits(ctx context.Context, source string) ([]int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var units []int64
+	err := r.db.SelectContext(ctx, &units, `SELECT unit FROM import_units WHERE source = $1 AND done ORDER BY unit`, source)
+	return units, err
+}
+
+// SaveImportUnit записывает результат попытки: errMsg = "" — единица загружена,
+// иначе она останется в очереди следующего запуска
+func (r *Repo) SaveImportUnit(ctx context.Context, source string, unit int64, movies int, errMsg string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO import_units (source, unit, done, attempts, movies, error)
+		VALUES ($1, $2, $4::text = '', 1, $3, NULLIF($4::text, ''))
+		ON CONFLICT (source, unit) DO UPDATE SET
+		  done       = EXCLUDED.done,
+		  attempts   = import_units.attempts + 1,
+		  movies     = EXCLUDED.movies,
+		  error      = EXCLUDED.error,
+		  updated_at = NOW()`, source, unit, movies, errMsg)
+	return err
+}
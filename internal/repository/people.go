@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- People ---
+
+// ReplaceMovieCast сохраняет людей и заменяет состав фильма. Порядок cast —
+// порядок в титрах; повторы (человек с той же профессией) пропускаются.
+func (r *Repo) ReplaceMovieCast(ctx context.Context, movieID int64, cast []models.CastMember) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM movie_people WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+	for i, c := range cast {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO people (person_id, name, name_en, photo_url) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (person_id) DO UPDATE SET
+			  name       = EXCLUDED.name,
+			  name_en    = COALESCE(NULLIF(EXCLUDED.name_en, ''), people.name_en),
+			  photo_url  = COALESCE(NULLIF(EXCLUDED.photo_url, ''), people.photo_url),
+			  updated_at = NOW()`,
+			c.PersonID, c.Name, c.NameEn, c.PhotoURL); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO movie_people (movie_id, person_id, profession, role, position)
+			VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`,
+			movieID, c.PersonID, c.Profession, c.Role, i); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE movies SET people_synced_at = NOW() WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MovieCastSynced сообщает, загружался ли уже состав фильма
+func (r *Repo) MovieCastSynced(ctx context.Context, movieID int64) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var synced bool
+	err := r.db.GetContext(ctx, &synced, `SELECT people_synced_at IS NOT NULL FROM movies WHERE movie_id = $1`, movieID)
+	return synced, err
+}
+
+// GetMovieCast возвращает состав фильма в порядке титров
+func (r *Repo) GetMovieCast(ctx context.Context, movieID int64) ([]models.CastMember, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var cast []models.CastMember
+	err := r.db.SelectContext(ctx, &cast, `
+		SELECT p.person_id, p.name, p.name_en, p.photo_url, mp.profession, mp.role
+		FROM movie_people mp JOIN people p ON p.person_id = mp.person_id
+		WHERE mp.movie_id = $1
+		ORDER BY mp.position, p.person_id`, movieID)
+	return cast, err
+}
+
+const personColumns = `p.person_id, p.name, p.name_en, p.photo_url,
+        (SELECT COUNT(DISTINCT mp.movie_id) FROM movie_people mp WHERE mp.person_id = p.person_id) AS movies_count`
+
+// GetPerson возвращает человека по ID (sql.ErrNoRows, если его нет)
+func (r *Repo) GetPerson(ctx context.Context, id int64) (*models.Person, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var p models.Person
+	if err := r.db.GetContext(ctx, &p, `SELECT `+personColumns+` FROM people p WHERE p.person_id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPersonMovies возвращает фильмографию человека в каталоге, новые фильмы первыми
+func (r *Repo) GetPersonMovies(ctx context.Context, personID int64) ([]models.PersonMovie, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var movies []models.PersonMovie
+	err := r.db.SelectContext(ctx, &movies, `
+		SELECT m.movie_id, m.title, COALESCE(m.year, 0) AS year, COALESCE(m.poster_url, '') AS poster_url,
+		       COALESCE(m.rating_kinopoisk, 0) AS rating_kinopoisk, mp.profession, mp.role
+		FROM movie_people mp JOIN movies m ON m.movie_id = mp.movie_id
+		WHERE mp.person_id = $1
+		ORDER BY m.year DESC NULLS LAST, m.movie_id, mp.profession`, personID)
+	return movies, err
+}
+
+const peopleSearchFrom = `
+      FROM people p
+      WHERE p.name ILIKE '%' || $1 || '%' OR p.name_en ILIKE '%' || $1 || '%'
+         OR p.name % $1 OR p.name_en % $1`
+
+// SearchPeople ищет людей по имени (русскому или английскому), прощая опечатки.
+// Точные совпадения подстроки и известные по большему числу фильмов — выше.
+func (r *Repo) SearchPeople(ctx context.Context, query string, offset, limit int) ([]models.Person, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*)"+peopleSearchFrom, query); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	var people []models.Person
+	err := r.db.SelectContext(ctx, &people, `SELECT `+personColumns+peopleSearchFrom+`
+      ORDER BY GREATEST(word_similarity($1, p.name), word_similarity($1, p.name_en)) DESC,
+               movies_count DESC, p.person_id
+      LIMIT $2 OFFSET $3`, query, limit, offset)
+	return people, total, err
+}
@@ -1,40 +1,77 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/AlexKeyyyy/movies-picker/internal/models"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// ErrDuplicate — нарушено ограничение уникальности (например, email уже занят)
+var ErrDuplicate = errors.New("duplicate key")
+
+// ErrMovieRemoved — модератор удалил фильм из каталога, и заново он не сохраняется
+var ErrMovieRemoved = errors.New("movie was removed from the catalog")
+
 type Repo struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	queryTimeout time.Duration // дедлайн одной операции с БД; 0 — только дедлайн вызывающего
 }
 
-func NewRepo(dbURL string) (*Repo, error) {
+func NewRepo(dbURL string, queryTimeout time.Duration) (*Repo, error) {
 	db, err := sqlx.Connect("postgres", dbURL)
 	if err != nil {
 		return nil, err
 	}
-	return &Repo{db: db}, nil
+	return &Repo{db: db, queryTimeout: queryTimeout}, nil
+}
+
+// withTimeout ограничивает операцию с БД дедлайном queryTimeout
+func (r *Repo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.queryTimeout)
 }
 
 // --- User ---
-func (r *Repo) CreateUser(u *models.User) error {
-	return r.db.Get(&u.ID,
+func (r *Repo) CreateUser(ctx context.Context, u *models.User) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	err := r.db.GetContext(ctx, &u.ID,
 		`INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING user_id`,
 		u.Email, u.PasswordHash)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
 }
 
-func (r *Repo) GetUserByEmail(email string) (*models.User, error) {
+// isUniqueViolation распознаёт ошибку Postgres unique_violation (23505)
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (r *Repo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	var u models.User
-	err := r.db.Get(&u, "SELECT * FROM users WHERE email=$1", email)
+	err := r.db.GetContext(ctx, &u, "SELECT * FROM users WHERE email=$1", email)
 	return &u, err
 }
 
 // GetUserByID возвращает пользователя по ID
-func (r *Repo) GetUserByID(userID int64) (*models.User, error) {
+func (r *Repo) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	var user models.User
-	err := r.db.Get(&user, "SELECT user_id, email, created_at FROM users WHERE user_id = $1", userID)
+	err := r.db.GetContext(ctx, &user, "SELECT user_id, email, created_at, role, disabled_at, email_verified_at FROM users WHERE user_id = $1", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -43,8 +80,10 @@ func (r *Repo) GetUserByID(userID int64) (*models.User, error) {
 
 // UpdateUser обновляет email и/или пароль пользователя
 // UpdateUser обновляет email и/или пароль пользователя
-func (r *Repo) UpdateUser(user *models.User) error {
-	_, err := r.db.Exec(
+func (r *Repo) UpdateUser(ctx context.Context, user *models.User) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx,
 		"UPDATE users SET email = $1, password_hash = $2 WHERE user_id = $3",
 		user.Email, user.PasswordHash, user.ID,
 	)
@@ -52,109 +91,564 @@ func (r *Repo) UpdateUser(user *models.User) error {
 }
 
 // --- Movie ---
-func (r *Repo) UpsertMovie(m *models.Movie) error {
+
+// movieColumns — колонки movies без служебного search_vector
+const movieColumns = `movie_id, title, year, poster_url, description, rating_kinopoisk, last_sync,
+        title_en, original_title, runtime, age_rating, rating_imdb, film_type`
+
+func (r *Repo) UpsertMovie(ctx context.Context, m *models.Movie) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// INSERT … ON CONFLICT (movie_id) DO UPDATE …
-	_, err := r.db.NamedExec(`
+	// пустые значения расширенных полей не затирают уже сохранённые
+	// (например, в подборках Кинопоиска нет длительности), а поля, исправленные
+	// модератором (edited_fields), не меняются вовсе. Удалённые модератором
+	// фильмы (removed_movies) не вставляются.
+	res, err := tx.NamedExecContext(ctx, `
       INSERT INTO movies
-        (movie_id, title, year, poster_url, description, rating_kinopoisk, last_sync)
-      VALUES
-        (:movie_id, :title, :year, :poster_url, :description, :rating_kinopoisk, NOW())
+        (movie_id, title, year, poster_url, description, rating_kinopoisk, last_sync,
+         title_en, original_title, runtime, age_rating, rating_imdb, film_type)
+      SELECT
+         :movie_id, :title, :year, :poster_url, :description, :rating_kinopoisk, NOW(),
+         :title_en, :original_title, :runtime, :age_rating, :rating_imdb, :film_type
+      WHERE NOT EXISTS (SELECT 1 FROM removed_movies WHERE movie_id = :movie_id)
       ON CONFLICT (movie_id) DO UPDATE SET
-        title            = EXCLUDED.title,
-        year             = EXCLUDED.year,
-        poster_url       = EXCLUDED.poster_url,
-        description      = EXCLUDED.description,
+        title            = CASE WHEN 'title' = ANY(movies.edited_fields) THEN movies.title
+                                ELSE EXCLUDED.title END,
+        year             = CASE WHEN 'year' = ANY(movies.edited_fields) THEN movies.year
+                                ELSE EXCLUDED.year END,
+        poster_url       = CASE WHEN 'poster_url' = ANY(movies.edited_fields) THEN movies.poster_url
+                                ELSE EXCLUDED.poster_url END,
+        description      = CASE WHEN 'description' = ANY(movies.edited_fields) THEN movies.description
+                                ELSE EXCLUDED.description END,
         rating_kinopoisk = EXCLUDED.rating_kinopoisk,
-        last_sync        = NOW()`,
+        last_sync        = NOW(),
+        title_en         = CASE WHEN 'title_en' = ANY(movies.edited_fields) THEN movies.title_en
+                                ELSE COALESCE(NULLIF(EXCLUDED.title_en, ''), movies.title_en) END,
+        original_title   = CASE WHEN 'original_title' = ANY(movies.edited_fields) THEN movies.original_title
+                                ELSE COALESCE(NULLIF(EXCLUDED.original_title, ''), movies.original_title) END,
+        runtime          = CASE WHEN 'runtime' = ANY(movies.edited_fields) THEN movies.runtime
+                                ELSE COALESCE(NULLIF(EXCLUDED.runtime, 0), movies.runtime) END,
+        age_rating       = COALESCE(NULLIF(EXCLUDED.age_rating, ''), movies.age_rating),
+        rating_imdb      = COALESCE(NULLIF(EXCLUDED.rating_imdb, 0), movies.rating_imdb),
+        film_type        = COALESCE(NULLIF(EXCLUDED.film_type, ''), movies.film_type)`,
 		m,
 	)
-	return err
+	if err := expectAffected(res, err); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMovieRemoved
+		}
+		return err
+	}
+	if err := replaceMovieLinks(ctx, tx, "genres", "movie_genres", "genre_id", m.ID, m.Genres); err != nil {
+		return err
+	}
+	if err := replaceMovieLinks(ctx, tx, "countries", "movie_countries", "country_id", m.ID, m.Countries); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *Repo) GetMovieByID(id int64) (*models.Movie, error) {
+// replaceMovieLinks заменяет связи фильма со справочником (жанры, страны).
+// Пустой список не трогает уже сохранённые связи.
+func replaceMovieLinks(ctx context.Context, tx *sqlx.Tx, dict, link, idCol string, movieID int64, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE movie_id = $1", link), movieID); err != nil {
+		return err
+	}
+	for _, name := range names {
+		var id int64
+		err := tx.GetContext(ctx, &id, fmt.Sprintf(
+			`INSERT INTO %s (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING %s`,
+			dict, idCol), name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"INSERT INTO %s (movie_id, %s) VALUES ($1, $2) ON CONFLICT DO NOTHING", link, idCol),
+			movieID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachMovieTags заполняет жанры и страны для переданных фильмов
+func (r *Repo) attachMovieTags(ctx context.Context, movies []models.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(movies))
+	idx := make(map[int64]int, len(movies))
+	for i, m := range movies {
+		ids[i] = m.ID
+		idx[m.ID] = i
+	}
+
+	type tag struct {
+		MovieID int64  `db:"movie_id"`
+		Name    string `db:"name"`
+	}
+	load := func(q string) ([]tag, error) {
+		query, args, err := sqlx.In(q, ids)
+		if err != nil {
+			return nil, err
+		}
+		var tags []tag
+		err = r.db.SelectContext(ctx, &tags, r.db.Rebind(query), args...)
+		return tags, err
+	}
+
+	genres, err := load(`
+        SELECT mg.movie_id, g.name FROM movie_genres mg JOIN genres g ON g.genre_id = mg.genre_id
+        WHERE mg.movie_id IN (?) ORDER BY g.name`)
+	if err != nil {
+		return err
+	}
+	for _, t := range genres {
+		i := idx[t.MovieID]
+		movies[i].Genres = append(movies[i].Genres, t.Name)
+	}
+
+	countries, err := load(`
+        SELECT mc.movie_id, c.name FROM movie_countries mc JOIN countries c ON c.country_id = mc.country_id
+        WHERE mc.movie_id IN (?) ORDER BY c.name`)
+	if err != nil {
+		return err
+	}
+	for _, t := range countries {
+		i := idx[t.MovieID]
+		movies[i].Countries = append(movies[i].Countries, t.Name)
+	}
+	return nil
+}
+
+func (r *Repo) GetMovieByID(ctx context.Context, id int64) (*models.Movie, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	var m models.Movie
-	err := r.db.Get(&m, "SELECT * FROM movies WHERE movie_id=$1", id)
-	return &m, err
+	if err := r.db.GetContext(ctx, &m, "SELECT "+movieColumns+" FROM movies WHERE movie_id=$1", id); err != nil {
+		return &m, err
+	}
+	movies := []models.Movie{m}
+	if err := r.attachMovieTags(ctx, movies); err != nil {
+		return &m, err
+	}
+	return &movies[0], nil
+}
+
+// MovieExists проверяет, есть ли фильм в каталоге
+func (r *Repo) MovieExists(ctx context.Context, id int64) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM movies WHERE movie_id = $1)`, id)
+	return exists, err
 }
 
-func (r *Repo) SearchMovies(query string) ([]models.Movie, error) {
-	// полнотекстовый поиск или ILIKE
+const (
+	ftsSearchFrom = `
+      FROM movies m, (
+        SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS tsq
+      ) q
+      WHERE m.search_vector @@ q.tsq`
+	trgmSearchFrom = `
+      FROM movies m
+      WHERE m.title % $1 OR m.original_title % $1`
+)
+
+// SearchMovies ищет фильмы полнотекстовым поиском (русская и английская
+// морфология) по названиям и описанию и сортирует по ts_rank с бонусом за
+// похожесть названия. Если ничего не нашлось — ищет по триграммам, чтобы
+// прощать опечатки. Возвращает страницу результатов и общее число найденных.
+func (r *Repo) SearchMovies(ctx context.Context, query string, offset, limit int) ([]models.Movie, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*)"+ftsSearchFrom, query); err != nil {
+		return nil, 0, err
+	}
+	if total > 0 {
+		var movies []models.Movie
+		err := r.db.SelectContext(ctx, &movies, `SELECT `+movieColumns+ftsSearchFrom+`
+      ORDER BY ts_rank(m.search_vector, q.tsq)
+               + GREATEST(similarity(m.title, $1), similarity(m.original_title, $1)) DESC,
+               m.rating_kinopoisk DESC NULLS LAST, m.movie_id
+      LIMIT $2 OFFSET $3`, query, limit, offset)
+		return movies, total, err
+	}
+
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*)"+trgmSearchFrom, query); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
 	var movies []models.Movie
-	err := r.db.Select(&movies, "SELECT * FROM movies WHERE title ILIKE $1", "%"+query+"%")
-	return movies, err
+	err := r.db.SelectContext(ctx, &movies, `SELECT `+movieColumns+trgmSearchFrom+`
+      ORDER BY GREATEST(similarity(m.title, $1), similarity(m.original_title, $1)) DESC,
+               m.rating_kinopoisk DESC NULLS LAST, m.movie_id
+      LIMIT $2 OFFSET $3`, query, limit, offset)
+	return movies, total, err
 }
 
-// ListMovies возвращает список фильмов с пагинацией
-func (r *Repo) ListMovies(offset, limit int) ([]models.Movie, error) {
+// GetMoviesByIDs возвращает фильмы по списку ID (порядок не гарантируется)
+func (r *Repo) GetMoviesByIDs(ctx context.Context, ids []int64) ([]models.Movie, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In("SELECT "+movieColumns+" FROM movies WHERE movie_id IN (?)", ids)
+	if err != nil {
+		return nil, err
+	}
 	var movies []models.Movie
+	err = r.db.SelectContext(ctx, &movies, r.db.Rebind(query), args...)
+	return movies, err
+}
+
+// ListMovies возвращает отфильтрованный и отсортированный список фильмов с пагинацией
+func (r *Repo) ListMovies(ctx context.Context, f models.MovieFilter, offset, limit int) ([]models.Movie, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var b whereBuilder
+	addMovieConditions(&b, f)
 	// выбираем только поля нужные для списка
 	query := `
-      SELECT movie_id, title, year, poster_url, description, rating_kinopoisk
-      FROM movies
-      ORDER BY title
-      LIMIT $1 OFFSET $2`
-	if err := r.db.Select(&movies, query, limit, offset); err != nil {
+      SELECT movie_id, title, year, poster_url, description, rating_kinopoisk,
+             original_title, runtime, rating_imdb, film_type
+      FROM movies m` + b.where() + movieOrderBy(f)
+	query += " LIMIT " + b.arg(limit) + " OFFSET " + b.arg(offset)
+
+	var movies []models.Movie
+	if err := r.db.SelectContext(ctx, &movies, query, b.args...); err != nil {
 		return nil, err
 	}
 	return movies, nil
 }
 
-// ListPopularMovies возвращает топ-N фильмов по рейтингу
-func (r *Repo) ListPopularMovies(limit int) ([]models.Movie, error) {
+// CountMovies возвращает число фильмов, подходящих под фильтр
+func (r *Repo) CountMovies(ctx context.Context, f models.MovieFilter) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var b whereBuilder
+	addMovieConditions(&b, f)
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM movies m"+b.where(), b.args...)
+	return total, err
+}
+
+// GetMovieFacets считает фасеты по жанрам и десятилетиям для фильтра.
+// Фасет не учитывает собственный фильтр, чтобы клиент видел альтернативы.
+func (r *Repo) GetMovieFacets(ctx context.Context, f models.MovieFilter) (*models.MovieFacets, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	facets := &models.MovieFacets{
+		Genres:  []models.FacetCount{},
+		Decades: []models.FacetCount{},
+	}
+
+	gf := f
+	gf.Genre = ""
+	var gb whereBuilder
+	addMovieConditions(&gb, gf)
+	err := r.db.SelectContext(ctx, &facets.Genres, `
+      SELECT g.name AS value, COUNT(*) AS count
+      FROM movies m
+      JOIN movie_genres mg ON mg.movie_id = m.movie_id
+      JOIN genres g ON g.genre_id = mg.genre_id`+gb.where()+`
+      GROUP BY g.name ORDER BY count DESC, value`, gb.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	df := f
+	df.YearFrom, df.YearTo = 0, 0
+	var db whereBuilder
+	db.add("m.year > 0")
+	addMovieConditions(&db, df)
+	err = r.db.SelectContext(ctx, &facets.Decades, `
+      SELECT ((m.year / 10) * 10)::text AS value, COUNT(*) AS count
+      FROM movies m`+db.where()+`
+      GROUP BY m.year / 10 ORDER BY m.year / 10`, db.args...)
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// ListPopularMovies возвращает страницу фильмов, отсортированных по рейтингу
+func (r *Repo) ListPopularMovies(ctx context.Context, offset, limit int) ([]models.Movie, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	var movies []models.Movie
-	err := r.db.Select(&movies,
+	err := r.db.SelectContext(ctx, &movies,
 		`SELECT movie_id, title, year, poster_url, rating_kinopoisk
-         FROM movies ORDER BY rating_kinopoisk DESC NULLS LAST LIMIT $1`,
-		limit,
+         FROM movies ORDER BY rating_kinopoisk DESC NULLS LAST, movie_id LIMIT $1 OFFSET $2`,
+		limit, offset,
 	)
 	return movies, err
 }
 
+// --- Picker ---
+
+const pickColumns = `
+        m.movie_id, m.title, COALESCE(m.year, 0) AS year,
+        COALESCE(m.poster_url, '') AS poster_url, COALESCE(m.description, '') AS description,
+        COALESCE(m.rating_kinopoisk, 0) AS rating_kinopoisk, m.last_sync,
+        m.original_title, m.runtime, m.film_type`
+
+// GetWatchlistPickCandidates возвращает фильмы из «Смотреть позже», подходящие под фильтр
+func (r *Repo) GetWatchlistPickCandidates(ctx context.Context, userID int64, f models.PickFilter) ([]models.PickCandidate, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var b whereBuilder
+	b.add("w.user_id = ?", userID)
+	addPickConditions(&b, f)
+	query := `SELECT` + pickColumns + `, w.added_at
+        FROM watchlist w JOIN movies m ON w.movie_id = m.movie_id` +
+		b.where() + " ORDER BY m.movie_id"
+
+	var list []models.PickCandidate
+	err := r.db.SelectContext(ctx, &list, query, b.args...)
+	return list, err
+}
+
+// GetCatalogPickCandidates возвращает до limit лучших по рейтингу фильмов каталога, подходящих под фильтр
+func (r *Repo) GetCatalogPickCandidates(ctx context.Context, f models.PickFilter, limit int) ([]models.PickCandidate, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var b whereBuilder
+	addPickConditions(&b, f)
+	query := `SELECT` + pickColumns + ` FROM movies m` + b.where() +
+		" ORDER BY m.rating_kinopoisk DESC NULLS LAST, m.movie_id LIMIT " + b.arg(limit)
+
+	var list []models.PickCandidate
+	err := r.db.SelectContext(ctx, &list, query, b.args...)
+	return list, err
+}
+
 // --- Watchlist ---
-func (r *Repo) AddToWatchlist(item *models.WatchlistItem) error {
-	_, err := r.db.Exec(`
+func (r *Repo) AddToWatchlist(ctx context.Context, item *models.WatchlistItem) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
         INSERT INTO watchlist (user_id, movie_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`,
 		item.UserID, item.MovieID)
 	return err
 }
 
-func (r *Repo) GetWatchlist(userID int64) ([]models.WatchlistItem, error) {
+func (r *Repo) GetWatchlist(ctx context.Context, userID int64) ([]models.WatchlistItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	var list []models.WatchlistItem
-	err := r.db.Select(&list, `
+	err := r.db.SelectContext(ctx, &list, `
         SELECT w.movie_id, w.added_at, m.title, m.poster_url
         FROM watchlist w JOIN movies m ON w.movie_id = m.movie_id
         WHERE w.user_id = $1`, userID)
 	return list, err
 }
 
-func (r *Repo) RemoveFromWatchlist(userID, movieID int64) error {
-	_, err := r.db.Exec(
+func (r *Repo) RemoveFromWatchlist(ctx context.Context, userID, movieID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx,
 		"DELETE FROM watchlist WHERE user_id=$1 AND movie_id=$2",
 		userID, movieID)
 	return err
 }
 
 // --- Ratings ---
-func (r *Repo) UpsertRating(item *models.RatingItem) error {
-	_, err := r.db.Exec(`
+func (r *Repo) UpsertRating(ctx context.Context, item *models.RatingItem) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
         INSERT INTO ratings (user_id, movie_id, rating) VALUES ($1,$2,$3)
         ON CONFLICT (user_id,movie_id) DO UPDATE SET rating = $3, rated_at = NOW()`,
 		item.UserID, item.MovieID, item.Rating)
 	return err
 }
 
-func (r *Repo) GetRatings(userID int64) ([]models.RatingItem, error) {
+func (r *Repo) GetRatings(ctx context.Context, userID int64) ([]models.RatingItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	var list []models.RatingItem
-	err := r.db.Select(&list,
+	err := r.db.SelectContext(ctx, &list,
 		"SELECT movie_id, rating, rated_at FROM ratings WHERE user_id=$1", userID)
 	return list, err
 }
 
 // DeleteRating удаляет оценку пользователя для фильма
-func (r *Repo) DeleteRating(userID, movieID int64) error {
-	_, err := r.db.Exec(
+func (r *Repo) DeleteRating(ctx context.Context, userID, movieID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx,
 		"DELETE FROM ratings WHERE user_id = $1 AND movie_id = $2",
 		userID, movieID,
 	)
 	return err
 }
+
+// GetNeighbourRatings возвращает оценки других пользователей, у которых
+// есть хотя бы один общий оценённый фильм с userID
+func (r *Repo) GetNeighbourRatings(ctx context.Context, userID int64) ([]models.RatingItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var list []models.RatingItem
+	err := r.db.SelectContext(ctx, &list, `
+        SELECT r.user_id, r.movie_id, r.rating, r.rated_at
+        FROM ratings r
+        WHERE r.user_id <> $1
+          AND r.user_id IN (
+            SELECT DISTINCT o.user_id FROM ratings o
+            JOIN ratings me ON me.movie_id = o.movie_id AND me.user_id = $1)`,
+		userID)
+	return list, err
+}
+
+// GetUsersByEmails возвращает пользователей с указанными email
+func (r *Repo) GetUsersByEmails(ctx context.Context, emails []string) ([]models.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In("SELECT user_id, email, created_at FROM users WHERE email IN (?)", emails)
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	err = r.db.SelectContext(ctx, &users, r.db.Rebind(query), args...)
+	return users, err
+}
+
+// GetWatchlistsForUsers возвращает записи «Смотреть позже» сразу нескольких пользователей
+func (r *Repo) GetWatchlistsForUsers(ctx context.Context, userIDs []int64) ([]models.WatchlistItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(`
+        SELECT w.user_id, w.movie_id, w.added_at, m.title, m.poster_url
+        FROM watchlist w JOIN movies m ON w.movie_id = m.movie_id
+        WHERE w.user_id IN (?)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	var list []models.WatchlistItem
+	err = r.db.SelectContext(ctx, &list, r.db.Rebind(query), args...)
+	return list, err
+}
+
+// --- Sessions ---
+
+// CreateSession создаёт сессию и приглашает участников; владелец сразу считается присоединившимся
+func (r *Repo) CreateSession(ctx context.Context, s *models.Session, inviteeIDs []int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx,
+		`INSERT INTO movie_sessions (owner_id, title) VALUES ($1, $2) RETURNING session_id, created_at`,
+		s.OwnerID, s.Title,
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO session_members (session_id, user_id, status, joined_at) VALUES ($1, $2, 'joined', NOW())`,
+		s.ID, s.OwnerID,
+	); err != nil {
+		return err
+	}
+	for _, uid := range inviteeIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO session_members (session_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			s.ID, uid,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *Repo) GetSession(ctx context.Context, sessionID int64) (*models.Session, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var s models.Session
+	err := r.db.GetContext(ctx, &s,
+		"SELECT session_id, owner_id, title, created_at FROM movie_sessions WHERE session_id = $1",
+		sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *Repo) GetSessionMembers(ctx context.Context, sessionID int64) ([]models.SessionMember, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var list []models.SessionMember
+	err := r.db.SelectContext(ctx, &list, `
+        SELECT sm.session_id, sm.user_id, u.email, sm.status, sm.joined_at
+        FROM session_members sm JOIN users u ON u.user_id = sm.user_id
+        WHERE sm.session_id = $1
+        ORDER BY sm.user_id`, sessionID)
+	return list, err
+}
+
+// JoinSession отмечает приглашённого участника присоединившимся.
+// Возвращает sql.ErrNoRows, если пользователь не приглашён.
+func (r *Repo) JoinSession(ctx context.Context, sessionID, userID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE session_members SET status = 'joined', joined_at = COALESCE(joined_at, NOW())
+        WHERE session_id = $1 AND user_id = $2`,
+		sessionID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repo) UpsertSessionVote(ctx context.Context, v *models.SessionVote) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO session_votes (session_id, user_id, movie_id, kind) VALUES ($1,$2,$3,$4)
+        ON CONFLICT (session_id, user_id, movie_id) DO UPDATE SET kind = $4, voted_at = NOW()`,
+		v.SessionID, v.UserID, v.MovieID, v.Kind)
+	return err
+}
+
+func (r *Repo) GetSessionVotes(ctx context.Context, sessionID int64) ([]models.SessionVote, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var list []models.SessionVote
+	err := r.db.SelectContext(ctx, &list,
+		"SELECT session_id, user_id, movie_id, kind FROM session_votes WHERE session_id = $1",
+		sessionID)
+	return list, err
+}
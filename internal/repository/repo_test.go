@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -8,6 +10,7 @@ import (
 	"github.com/AlexKeyyyy/movies-picker/internal/models"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,7 +58,7 @@ func TestCreateUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.CreateUser(tt.user)
+			err := repo.CreateUser(context.Background(), tt.user)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -68,6 +71,21 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
+func TestCreateUser_Duplicate(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`INSERT INTO users`).
+		WithArgs("taken@example.com", "hash").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := repo.CreateUser(context.Background(), &models.User{Email: "taken@example.com", PasswordHash: "hash"})
+	assert.ErrorIs(t, err, ErrDuplicate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetUserByEmail(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -118,7 +136,7 @@ func TestGetUserByEmail(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetUserByEmail(tt.email)
+			got, err := repo.GetUserByEmail(context.Background(), tt.email)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -150,9 +168,9 @@ func TestGetUserByID(t *testing.T) {
 			name:   "Success",
 			userID: 1,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"user_id", "email", "created_at"}).
-					AddRow(1, "test@example.com", now)
-				mock.ExpectQuery("SELECT user_id, email, created_at FROM users WHERE user_id = \\$1").
+				rows := sqlmock.NewRows([]string{"user_id", "email", "created_at", "role", "disabled_at"}).
+					AddRow(1, "test@example.com", now, "user", nil)
+				mock.ExpectQuery("SELECT user_id, email, created_at, role, disabled_at, email_verified_at FROM users WHERE user_id = \\$1").
 					WithArgs(1).
 					WillReturnRows(rows)
 			},
@@ -160,6 +178,7 @@ func TestGetUserByID(t *testing.T) {
 				ID:        1,
 				Email:     "test@example.com",
 				CreatedAt: now,
+				Role:      "user",
 			},
 			wantErr: false,
 		},
@@ -167,7 +186,7 @@ func TestGetUserByID(t *testing.T) {
 			name:   "Not Found",
 			userID: 999,
 			mock: func() {
-				mock.ExpectQuery("SELECT user_id, email, created_at FROM users WHERE user_id = \\$1").
+				mock.ExpectQuery("SELECT user_id, email, created_at, role, disabled_at, email_verified_at FROM users WHERE user_id = \\$1").
 					WithArgs(999).
 					WillReturnError(errors.New("not found"))
 			},
@@ -180,7 +199,7 @@ func TestGetUserByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetUserByID(tt.userID)
+			got, err := repo.GetUserByID(context.Background(), tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -239,7 +258,7 @@ func TestUpdateUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.UpdateUser(tt.user)
+			err := repo.UpdateUser(context.Background(), tt.user)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -264,6 +283,18 @@ func TestUpsertMovie(t *testing.T) {
 		PosterURL:       "http://example.com/poster.jpg",
 		Description:     "Test description",
 		RatingKinopoisk: 7.5,
+		TitleEn:         "Test Movie EN",
+		OriginalTitle:   "Test Movie Original",
+		Runtime:         120,
+		AgeRating:       "age16",
+		RatingImdb:      7.1,
+		Type:            "FILM",
+	}
+	withTags := &models.Movie{
+		ID:        2,
+		Title:     "Tagged Movie",
+		Genres:    []string{"драма"},
+		Countries: []string{"США"},
 	}
 
 	tests := []struct {
@@ -276,6 +307,7 @@ func TestUpsertMovie(t *testing.T) {
 			name:  "Success",
 			movie: movie,
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(`INSERT INTO movies.*`).
 					WithArgs(
 						movie.ID,
@@ -284,8 +316,45 @@ func TestUpsertMovie(t *testing.T) {
 						movie.PosterURL,
 						movie.Description,
 						movie.RatingKinopoisk,
+						movie.TitleEn,
+						movie.OriginalTitle,
+						movie.Runtime,
+						movie.AgeRating,
+						movie.RatingImdb,
+						movie.Type,
+						movie.ID, // проверка removed_movies
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name:  "With Genres And Countries",
+			movie: withTags,
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO movies.*`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`DELETE FROM movie_genres WHERE movie_id = \$1`).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO genres \(name\) VALUES \(\$1\)`).
+					WithArgs("драма").
+					WillReturnRows(sqlmock.NewRows([]string{"genre_id"}).AddRow(5))
+				mock.ExpectExec(`INSERT INTO movie_genres \(movie_id, genre_id\)`).
+					WithArgs(2, 5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM movie_countries WHERE movie_id = \$1`).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO countries \(name\) VALUES \(\$1\)`).
+					WithArgs("США").
+					WillReturnRows(sqlmock.NewRows([]string{"country_id"}).AddRow(3))
+				mock.ExpectExec(`INSERT INTO movie_countries \(movie_id, country_id\)`).
+					WithArgs(2, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
@@ -293,8 +362,10 @@ func TestUpsertMovie(t *testing.T) {
 			name:  "Empty Title",
 			movie: &models.Movie{Title: ""},
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec(`INSERT INTO movies.*`).
 					WillReturnError(errors.New("empty title"))
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -304,7 +375,7 @@ func TestUpsertMovie(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.UpsertMovie(tt.movie)
+			err := repo.UpsertMovie(context.Background(), tt.movie)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -331,6 +402,8 @@ func TestGetMovieByID(t *testing.T) {
 		Description:     "Test description",
 		RatingKinopoisk: 7.5,
 		LastSync:        now,
+		Genres:          []string{"драма"},
+		Countries:       []string{"США"},
 	}
 
 	tests := []struct {
@@ -351,9 +424,15 @@ func TestGetMovieByID(t *testing.T) {
 					movie.ID, movie.Title, movie.Year, movie.PosterURL,
 					movie.Description, movie.RatingKinopoisk, movie.LastSync,
 				)
-				mock.ExpectQuery("SELECT \\* FROM movies WHERE movie_id=\\$1").
+				mock.ExpectQuery("SELECT movie_id, title, .+ FROM movies WHERE movie_id=\\$1").
 					WithArgs(1).
 					WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT mg.movie_id, g.name FROM movie_genres`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"movie_id", "name"}).AddRow(1, "драма"))
+				mock.ExpectQuery(`SELECT mc.movie_id, c.name FROM movie_countries`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"movie_id", "name"}).AddRow(1, "США"))
 			},
 			want:    movie,
 			wantErr: false,
@@ -362,7 +441,7 @@ func TestGetMovieByID(t *testing.T) {
 			name:    "Not Found",
 			movieID: 999,
 			mock: func() {
-				mock.ExpectQuery("SELECT \\* FROM movies WHERE movie_id=\\$1").
+				mock.ExpectQuery("SELECT movie_id, title, .+ FROM movies WHERE movie_id=\\$1").
 					WithArgs(999).
 					WillReturnError(errors.New("not found"))
 			},
@@ -375,7 +454,7 @@ func TestGetMovieByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetMovieByID(tt.movieID)
+			got, err := repo.GetMovieByID(context.Background(), tt.movieID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -413,48 +492,84 @@ func TestSearchMovies(t *testing.T) {
 		},
 	}
 
+	columns := []string{
+		"movie_id", "title", "year", "poster_url",
+		"description", "rating_kinopoisk", "last_sync",
+	}
+	ftsCount := `SELECT COUNT\(\*\)\s+FROM movies m, \(\s+SELECT websearch_to_tsquery\('russian', \$1\) \|\| websearch_to_tsquery\('english', \$1\) AS tsq`
+	ftsQuery := `SELECT movie_id, title, .+WHERE m.search_vector @@ q.tsq\s+ORDER BY ts_rank.+LIMIT \$2 OFFSET \$3`
+	trgmCount := `SELECT COUNT\(\*\)\s+FROM movies m\s+WHERE m.title % \$1 OR m.original_title % \$1`
+	trgmQuery := `SELECT movie_id, title, .+WHERE m.title % \$1 OR m.original_title % \$1.+LIMIT \$2 OFFSET \$3`
+	count := func(n int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"count"}).AddRow(n)
+	}
+
 	tests := []struct {
-		name    string
-		query   string
-		mock    func()
-		want    []models.Movie
-		wantErr bool
+		name      string
+		query     string
+		mock      func()
+		want      []models.Movie
+		wantTotal int
+		wantErr   bool
 	}{
 		{
-			name:  "Success",
+			name:  "Full Text Match",
 			query: "test",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{
-					"movie_id", "title", "year", "poster_url",
-					"description", "rating_kinopoisk", "last_sync",
-				})
+				mock.ExpectQuery(ftsCount).WithArgs("test").WillReturnRows(count(42))
+				rows := sqlmock.NewRows(columns)
 				for _, m := range movies {
 					rows.AddRow(
 						m.ID, m.Title, m.Year, m.PosterURL,
 						m.Description, m.RatingKinopoisk, m.LastSync,
 					)
 				}
-				mock.ExpectQuery("SELECT \\* FROM movies WHERE title ILIKE \\$1").
-					WithArgs("%test%").
+				mock.ExpectQuery(ftsQuery).
+					WithArgs("test", 10, 20).
 					WillReturnRows(rows)
 			},
-			want:    movies,
-			wantErr: false,
+			want:      movies,
+			wantTotal: 42,
+			wantErr:   false,
+		},
+		{
+			name:  "Typo Falls Back To Trigrams",
+			query: "tset movie",
+			mock: func() {
+				mock.ExpectQuery(ftsCount).WithArgs("tset movie").WillReturnRows(count(0))
+				mock.ExpectQuery(trgmCount).WithArgs("tset movie").WillReturnRows(count(1))
+				rows := sqlmock.NewRows(columns).AddRow(
+					movies[0].ID, movies[0].Title, movies[0].Year, movies[0].PosterURL,
+					movies[0].Description, movies[0].RatingKinopoisk, movies[0].LastSync,
+				)
+				mock.ExpectQuery(trgmQuery).
+					WithArgs("tset movie", 10, 20).
+					WillReturnRows(rows)
+			},
+			want:      movies[:1],
+			wantTotal: 1,
+			wantErr:   false,
 		},
 		{
 			name:  "No Results",
 			query: "nonexistent",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{
-					"movie_id", "title", "year", "poster_url",
-					"description", "rating_kinopoisk", "last_sync",
-				})
-				mock.ExpectQuery("SELECT \\* FROM movies WHERE title ILIKE \\$1").
-					WithArgs("%nonexistent%").
-					WillReturnRows(rows)
+				mock.ExpectQuery(ftsCount).WithArgs("nonexistent").WillReturnRows(count(0))
+				mock.ExpectQuery(trgmCount).WithArgs("nonexistent").WillReturnRows(count(0))
 			},
-			want:    nil,
-			wantErr: false,
+			want:      nil,
+			wantTotal: 0,
+			wantErr:   false,
+		},
+		{
+			name:  "Database Error",
+			query: "test",
+			mock: func() {
+				mock.ExpectQuery(ftsCount).
+					WithArgs("test").
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
 		},
 	}
 
@@ -462,12 +577,13 @@ func TestSearchMovies(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.SearchMovies(tt.query)
+			got, total, err := repo.SearchMovies(context.Background(), tt.query, 20, 10)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantTotal, total)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -552,7 +668,7 @@ func TestListMovies(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.ListMovies(tt.offset, tt.limit)
+			got, err := repo.ListMovies(context.Background(), models.MovieFilter{}, tt.offset, tt.limit)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -608,7 +724,7 @@ func TestListPopularMovies(t *testing.T) {
 					)
 				}
 				mock.ExpectQuery(`SELECT movie_id, title, year, poster_url, rating_kinopoisk`).
-					WithArgs(2).
+					WithArgs(2, 0).
 					WillReturnRows(rows)
 			},
 			want:    movies,
@@ -622,7 +738,7 @@ func TestListPopularMovies(t *testing.T) {
 					"movie_id", "title", "year", "poster_url", "rating_kinopoisk",
 				})
 				mock.ExpectQuery(`SELECT movie_id, title, year, poster_url, rating_kinopoisk`).
-					WithArgs(0).
+					WithArgs(0, 0).
 					WillReturnRows(rows)
 			},
 			want:    nil,
@@ -634,7 +750,7 @@ func TestListPopularMovies(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.ListPopularMovies(tt.limit)
+			got, err := repo.ListPopularMovies(context.Background(), 0, tt.limit)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -691,7 +807,7 @@ func TestAddToWatchlist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.AddToWatchlist(tt.item)
+			err := repo.AddToWatchlist(context.Background(), tt.item)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -765,7 +881,7 @@ func TestGetWatchlist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetWatchlist(tt.userID)
+			got, err := repo.GetWatchlist(context.Background(), tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -819,7 +935,7 @@ func TestRemoveFromWatchlist(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.RemoveFromWatchlist(tt.userID, tt.movieID)
+			err := repo.RemoveFromWatchlist(context.Background(), tt.userID, tt.movieID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -891,7 +1007,7 @@ func TestUpsertRating(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.UpsertRating(tt.item)
+			err := repo.UpsertRating(context.Background(), tt.item)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -963,7 +1079,7 @@ func TestGetRatings(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetRatings(tt.userID)
+			got, err := repo.GetRatings(context.Background(), tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -1017,14 +1133,1341 @@ func TestDeleteRating(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.DeleteRating(tt.userID, tt.movieID)
+			err := repo.DeleteRating(context.Background(), tt.userID, tt.movieID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetMoviesByIDs(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	tests := []struct {
+		name    string
+		ids     []int64
+		mock    func()
+		want    []models.Movie
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			ids:  []int64{1, 2},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"movie_id", "title"}).
+					AddRow(1, "Movie 1").
+					AddRow(2, "Movie 2")
+				mock.ExpectQuery(`SELECT movie_id, title, .+ FROM movies WHERE movie_id IN \(.+\)`).
+					WithArgs(1, 2).
+					WillReturnRows(rows)
+			},
+			want: []models.Movie{
+				{ID: 1, Title: "Movie 1"},
+				{ID: 2, Title: "Movie 2"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Empty IDs",
+			ids:     nil,
+			mock:    func() {},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.GetMoviesByIDs(context.Background(), tt.ids)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetNeighbourRatings(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	now := time.Now().Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		userID  int64
+		mock    func()
+		want    []models.RatingItem
+		wantErr bool
+	}{
+		{
+			name:   "Success",
+			userID: 1,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"user_id", "movie_id", "rating", "rated_at"}).
+					AddRow(2, 10, 9, now)
+				mock.ExpectQuery(`SELECT r.user_id, r.movie_id, r.rating, r.rated_at`).
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			want:    []models.RatingItem{{UserID: 2, MovieID: 10, Rating: 9, RatedAt: now}},
+			wantErr: false,
+		},
+		{
+			name:   "Database Error",
+			userID: 1,
+			mock: func() {
+				mock.ExpectQuery(`SELECT r.user_id, r.movie_id, r.rating, r.rated_at`).
+					WithArgs(1).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.GetNeighbourRatings(context.Background(), tt.userID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetWatchlistPickCandidates(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	added := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  models.PickFilter
+		mock    func()
+		want    []models.PickCandidate
+		wantErr bool
+	}{
+		{
+			name:   "With Filters",
+			filter: models.PickFilter{YearFrom: 1990, YearTo: 2000, MinRating: 7.5},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"movie_id", "title", "year", "rating_kinopoisk", "added_at"}).
+					AddRow(1, "Movie 1", 1994, 8.1, added)
+				mock.ExpectQuery(`FROM watchlist w JOIN movies m .* WHERE w.user_id = \$1 AND m.year >= \$2 AND m.year <= \$3 AND m.rating_kinopoisk >= \$4`).
+					WithArgs(1, 1990, 2000, 7.5).
+					WillReturnRows(rows)
+			},
+			want: []models.PickCandidate{
+				{Movie: models.Movie{ID: 1, Title: "Movie 1", Year: 1994, RatingKinopoisk: 8.1}, AddedAt: &added},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Database Error",
+			filter: models.PickFilter{},
+			mock: func() {
+				mock.ExpectQuery(`FROM watchlist w JOIN movies m .* WHERE w.user_id = \$1 ORDER BY`).
+					WithArgs(1).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := repo.GetWatchlistPickCandidates(context.Background(), 1, tt.filter)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetCatalogPickCandidates(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	rows := sqlmock.NewRows([]string{"movie_id", "title", "rating_kinopoisk"}).
+		AddRow(2, "Movie 2", 8.0)
+	mock.ExpectQuery(`FROM movies m WHERE m.rating_kinopoisk >= \$1 ORDER BY .* LIMIT \$2`).
+		WithArgs(7.0, 500).
+		WillReturnRows(rows)
+
+	got, err := repo.GetCatalogPickCandidates(context.Background(), models.PickFilter{MinRating: 7}, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PickCandidate{
+		{Movie: models.Movie{ID: 2, Title: "Movie 2", RatingKinopoisk: 8.0}},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateSession(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	now := time.Now().Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		invitees []int64
+		mock     func()
+		wantErr  bool
+	}{
+		{
+			name:     "Success",
+			invitees: []int64{2},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO movie_sessions \(owner_id, title\) VALUES \(\$1, \$2\) RETURNING session_id, created_at`).
+					WithArgs(1, "Friday").
+					WillReturnRows(sqlmock.NewRows([]string{"session_id", "created_at"}).AddRow(7, now))
+				mock.ExpectExec(`INSERT INTO session_members \(session_id, user_id, status, joined_at\)`).
+					WithArgs(7, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO session_members \(session_id, user_id\) VALUES \(\$1, \$2\)`).
+					WithArgs(7, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name:     "Invitee Insert Fails",
+			invitees: []int64{3},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO movie_sessions`).
+					WithArgs(1, "Friday").
+					WillReturnRows(sqlmock.NewRows([]string{"session_id", "created_at"}).AddRow(8, now))
+				mock.ExpectExec(`INSERT INTO session_members \(session_id, user_id, status, joined_at\)`).
+					WithArgs(8, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO session_members \(session_id, user_id\) VALUES`).
+					WithArgs(8, 3).
+					WillReturnError(errors.New("fk violation"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			s := &models.Session{OwnerID: 1, Title: "Friday"}
+			err := repo.CreateSession(context.Background(), s, tt.invitees)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, int64(7), s.ID)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestJoinSession(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(`UPDATE session_members SET status = 'joined'`).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Not Invited",
+			mock: func() {
+				mock.ExpectExec(`UPDATE session_members SET status = 'joined'`).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := repo.JoinSession(context.Background(), 1, 2)
+			assert.Equal(t, tt.wantErr, err)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetUsersByEmails(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	now := time.Now().Format(time.RFC3339)
+	rows := sqlmock.NewRows([]string{"user_id", "email", "created_at"}).
+		AddRow(2, "a@example.com", now)
+	mock.ExpectQuery(`SELECT user_id, email, created_at FROM users WHERE email IN \(.+\)`).
+		WithArgs("a@example.com", "b@example.com").
+		WillReturnRows(rows)
+
+	got, err := repo.GetUsersByEmails(context.Background(), []string{"a@example.com", "b@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.User{{ID: 2, Email: "a@example.com", CreatedAt: now}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMovies_FiltersAndSort(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	f := models.MovieFilter{
+		YearFrom:   1990,
+		RatingFrom: 7,
+		Genre:      "драма",
+		Country:    "США",
+		Type:       "film",
+		Sort:       "rating",
+		Order:      "desc",
+	}
+	rows := sqlmock.NewRows([]string{"movie_id", "title"}).AddRow(1, "Movie 1")
+	mock.ExpectQuery(`FROM movies m WHERE m.year >= \$1 AND m.rating_kinopoisk >= \$2 AND EXISTS .*LOWER\(\$3\)\) AND EXISTS .*LOWER\(\$4\)\) AND m.film_type = \$5 ORDER BY m.rating_kinopoisk DESC NULLS LAST, m.movie_id LIMIT \$6 OFFSET \$7`).
+		WithArgs(1990, 7.0, "драма", "США", "FILM", 20, 40).
+		WillReturnRows(rows)
+
+	got, err := repo.ListMovies(context.Background(), f, 40, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Movie{{ID: 1, Title: "Movie 1"}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMovies_UnknownSortFallsBackToTitle(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`FROM movies m ORDER BY m.title ASC NULLS LAST, m.movie_id LIMIT \$1 OFFSET \$2`).
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"movie_id"}))
+
+	_, err := repo.ListMovies(context.Background(), models.MovieFilter{Sort: "title; DROP TABLE movies"}, 0, 10)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMovieFacets(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	f := models.MovieFilter{YearFrom: 1990, Genre: "драма"}
+
+	// фасет жанров игнорирует фильтр по жанру
+	mock.ExpectQuery(`SELECT g.name AS value, COUNT\(\*\) AS count .* WHERE m.year >= \$1 GROUP BY g.name`).
+		WithArgs(1990).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).
+			AddRow("драма", 12).
+			AddRow("комедия", 5))
+	// фасет десятилетий игнорирует диапазон лет
+	mock.ExpectQuery(`SELECT \(\(m.year / 10\) \* 10\)::text AS value.* WHERE m.year > 0 AND EXISTS .*LOWER\(\$1\)\) GROUP BY`).
+		WithArgs("драма").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).
+			AddRow("1990", 4).
+			AddRow("2000", 8))
+
+	got, err := repo.GetMovieFacets(context.Background(), f)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.MovieFacets{
+		Genres:  []models.FacetCount{{Value: "драма", Count: 12}, {Value: "комедия", Count: 5}},
+		Decades: []models.FacetCount{{Value: "1990", Count: 4}, {Value: "2000", Count: 8}},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountMovies(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM movies m WHERE m.year >= \$1`).
+		WithArgs(2000).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(17))
+
+	got, err := repo.CountMovies(context.Background(), models.MovieFilter{YearFrom: 2000})
+	assert.NoError(t, err)
+	assert.Equal(t, 17, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRefreshToken(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`FROM refresh_tokens rt JOIN auth_sessions s ON s.session_id = rt.session_id JOIN users u ON u.user_id = s.user_id WHERE rt.token_hash = \$1`).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"token_hash", "session_id", "user_id", "expired", "used", "session_revoked", "role", "user_disabled"}).
+			AddRow("hash", "sess", 3, false, true, false, "admin", false))
+
+	got, err := repo.GetRefreshToken(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.RefreshToken{TokenHash: "hash", SessionID: "sess", UserID: 3, Used: true, Role: "admin"}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		rotated bool
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE token_hash = \$1 AND used_at IS NULL`).
+					WithArgs("old").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO refresh_tokens`).
+					WithArgs("new", "sess", int64(3600)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			rotated: true,
+		},
+		{
+			name: "Already used",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE refresh_tokens SET used_at`).
+					WithArgs("old").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			rotated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			rotated, err := repo.RotateRefreshToken(context.Background(), "old", "new", "sess", time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.rotated, rotated)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIsAccessTokenRevoked(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM revoked_tokens WHERE jti = \$1\)\s+OR EXISTS \(SELECT 1 FROM auth_sessions WHERE session_id = \$2 AND revoked_at IS NOT NULL\)`).
+		WithArgs("jti", "sess").
+		WillReturnRows(sqlmock.NewRows([]string{"revoked"}).AddRow(true))
+
+	revoked, err := repo.IsAccessTokenRevoked(context.Background(), "jti", "sess")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMovie_Removed(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	// удалённый модератором фильм не вставляется заново
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO movies .+ SELECT .+ WHERE NOT EXISTS \(SELECT 1 FROM removed_movies WHERE movie_id = \?\) ON CONFLICT`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.UpsertMovie(context.Background(), &models.Movie{ID: 301, Title: "Matrix", Genres: []string{"драма"}})
+	assert.Equal(t, ErrMovieRemoved, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMovie_KeepsEditedFields(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	// поля, которые правит модератор, обновляются, только если их не исправляли
+	query := `INSERT INTO movies .+ ON CONFLICT \(movie_id\) DO UPDATE SET`
+	for _, col := range []string{"title", "year", "poster_url", "description", "title_en", "original_title", "runtime"} {
+		query += `.* ` + col + ` = CASE WHEN '` + col + `' = ANY\(movies.edited_fields\) THEN movies.` + col + ` ELSE`
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpsertMovie(context.Background(), &models.Movie{ID: 301, Title: "Matrix"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMovieFields(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	title, year := "Матрица", 1999
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				// исправленные поля запоминаются, чтобы обновление из Kinopoisk их не затёрло
+				mock.ExpectExec(`UPDATE movies SET title = \$1, year = \$2, edited_fields = ARRAY\(SELECT DISTINCT unnest\(edited_fields \|\| \$3::text\[\]\)\) WHERE movie_id = \$4`).
+					WithArgs(title, year, pq.Array([]string{"title", "year"}), int64(301)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectExec(`UPDATE movies SET title = \$1, year = \$2, edited_fields = .+ WHERE movie_id = \$4`).
+					WithArgs(title, year, pq.Array([]string{"title", "year"}), int64(301)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.UpdateMovieFields(context.Background(), 301, models.MoviePatch{Title: &title, Year: &year})
+			assert.Equal(t, tt.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteMovie(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM movies WHERE movie_id = \$1`).
+		WithArgs(int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO removed_movies \(movie_id, merged_into\) VALUES \(\$1, \$2\)`).
+		WithArgs(int64(301), nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.DeleteMovie(context.Background(), 301))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM movies WHERE movie_id = \$1`).
+		WithArgs(int64(302)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.DeleteMovie(context.Background(), 302), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRemovedMovie(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+	ctx := context.Background()
+
+	query := `SELECT merged_into FROM removed_movies WHERE movie_id = \$1`
+	mock.ExpectQuery(query).WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"merged_into"}).AddRow(int64(1)))
+	mock.ExpectQuery(query).WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"merged_into"}).AddRow(nil))
+	mock.ExpectQuery(query).WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"merged_into"}))
+
+	target, err := repo.GetRemovedMovie(ctx, 2)
+	if assert.NoError(t, err) && assert.NotNil(t, target) {
+		assert.Equal(t, int64(1), *target)
+	}
+	target, err = repo.GetRemovedMovie(ctx, 3)
+	assert.NoError(t, err)
+	assert.Nil(t, target, "deleted, not merged")
+	_, err = repo.GetRemovedMovie(ctx, 4)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeMovies(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM movies WHERE movie_id IN \(\$1, \$2\)`).
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	// голоса и жалобы на рецензии, которые не переносятся, отбрасываются до копирования оценок
+	for _, table := range []string{"review_votes", "review_reports"} {
+		mock.ExpectExec(`DELETE FROM `+table+` v WHERE v.movie_id = \$1 AND EXISTS \(SELECT 1 FROM ratings t WHERE t.movie_id = \$2 AND t.user_id = v.author_id\)`).
+			WithArgs(int64(2), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	for _, table := range []string{"watchlist", "ratings", "review_votes", "review_reports", "hidden_reviews", "session_votes", "movie_genres", "movie_countries", "movie_people"} {
+		mock.ExpectExec(`INSERT INTO `+table+` \(movie_id, .+\) SELECT \$2, .+ FROM `+table+` WHERE movie_id = \$1 ON CONFLICT DO NOTHING`).
+			WithArgs(int64(2), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	// ID дубля и слитых с ним раньше фильмов ведут на целевой фильм
+	mock.ExpectExec(`UPDATE removed_movies SET merged_into = \$2 WHERE merged_into = \$1`).
+		WithArgs(int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM movies WHERE movie_id = \$1`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO removed_movies \(movie_id, merged_into\) VALUES \(\$1, \$2\) ON CONFLICT \(movie_id\) DO UPDATE SET merged_into = EXCLUDED.merged_into`).
+		WithArgs(int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.MergeMovies(context.Background(), 2, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeMovies_ReviewVotesFail(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM movies`).
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	// без очистки голосов оценки не переносятся
+	mock.ExpectExec(`DELETE FROM review_votes`).
+		WithArgs(int64(2), int64(1)).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	assert.Equal(t, sql.ErrConnDone, repo.MergeMovies(context.Background(), 2, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeMovies_MissingMovie(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM movies`).
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	assert.Equal(t, sql.ErrNoRows, repo.MergeMovies(context.Background(), 2, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetUserDisabled(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectExec(`UPDATE users SET disabled_at = CASE WHEN \$1 THEN COALESCE\(disabled_at, CURRENT_TIMESTAMP\) END WHERE user_id = \$2`).
+		WithArgs(true, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SetUserDisabled(context.Background(), 5, true))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	// остальные сессии и их refresh-токены отзываются, текущая остаётся
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET password_hash = \$1 WHERE user_id = \$2`).
+		WithArgs("hash", int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE auth_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = \$1 AND session_id <> \$2 AND revoked_at IS NULL`).
+		WithArgs(int64(4), "sid-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.ChangePassword(context.Background(), 4, "hash", "sid-1"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET password_hash = \$1`).
+		WithArgs("hash", int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.ChangePassword(context.Background(), 5, "hash", "sid-1"), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserToken(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectBegin()
+	// прежние токены того же назначения гасятся
+	mock.ExpectExec(`UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = \$1 AND purpose = \$2 AND used_at IS NULL`).
+		WithArgs(int64(4), models.TokenResetPassword).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO user_tokens \(token_hash, user_id, purpose, email, expires_at\)`).
+		WithArgs("hash", int64(4), models.TokenResetPassword, "a@b.ru", int64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.CreateUserToken(context.Background(), "hash", 4, models.TokenResetPassword, "a@b.ru", time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsumeUserToken(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	query := `UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP WHERE token_hash = \$1 AND purpose = \$2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP RETURNING user_id, email`
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    *models.UserToken
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("hash", models.TokenVerifyEmail).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "email"}).AddRow(4, "new@b.ru"))
+			},
+			want: &models.UserToken{UserID: 4, Email: "new@b.ru"},
+		},
+		{
+			name: "Used or expired",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("hash", models.TokenVerifyEmail).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "email"}))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.ConsumeUserToken(context.Background(), "hash", models.TokenVerifyEmail)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMovieExists(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM movies WHERE movie_id = \$1\)`).
+		WithArgs(int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	exists, err := repo.MovieExists(context.Background(), 301)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListStaleMovies(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`SELECT m.movie_id FROM movies m WHERE \(last_sync IS NULL OR last_sync < NOW\(\) - make_interval\(secs => \$1\)\) ORDER BY \(EXISTS .+ DESC, m.last_sync NULLS FIRST, m.movie_id LIMIT \$2`).
+		WithArgs(float64(86400), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"movie_id"}).AddRow(301).AddRow(326))
+
+	ids, err := repo.ListStaleMovies(context.Background(), 24*time.Hour, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{301, 326}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveImportUnit(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectExec(`INSERT INTO import_units .+ ON CONFLICT \(source, unit\) DO UPDATE SET done = EXCLUDED.done, attempts = import_units.attempts \+ 1`).
+		WithArgs("collection:TOP_250_MOVIES", int64(3), 0, "unexpected status code: 500").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT unit FROM import_units WHERE source = \$1 AND done ORDER BY unit`).
+		WithArgs("collection:TOP_250_MOVIES").
+		WillReturnRows(sqlmock.NewRows([]string{"unit"}).AddRow(1).AddRow(2))
+
+	assert.NoError(t, repo.SaveImportUnit(context.Background(), "collection:TOP_250_MOVIES", 3, 0, "unexpected status code: 500"))
+	units, err := repo.ListDoneImportUnits(context.Background(), "collection:TOP_250_MOVIES")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, units)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceMovieCast(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	cast := []models.CastMember{
+		{PersonID: 7836, Name: "Лана Вачовски", Profession: "DIRECTOR"},
+		{PersonID: 7140, Name: "Киану Ривз", NameEn: "Keanu Reeves", Profession: "ACTOR", Role: "Neo"},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM movie_people WHERE movie_id = \$1`).
+		WithArgs(int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	for i, c := range cast {
+		mock.ExpectExec(`INSERT INTO people \(person_id, name, name_en, photo_url\) .+ ON CONFLICT \(person_id\) DO UPDATE`).
+			WithArgs(c.PersonID, c.Name, c.NameEn, c.PhotoURL).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO movie_people \(movie_id, person_id, profession, role, position\)`).
+			WithArgs(int64(301), c.PersonID, c.Profession, c.Role, i).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`UPDATE movies SET people_synced_at = NOW\(\) WHERE movie_id = \$1`).
+		WithArgs(int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.ReplaceMovieCast(context.Background(), 301, cast))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMovies_PersonFilter(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`FROM movies m WHERE EXISTS \( SELECT 1 FROM movie_people mp WHERE mp.movie_id = m.movie_id AND mp.person_id = \$1\) ORDER BY`).
+		WithArgs(int64(7140), 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"movie_id"}).AddRow(301))
+	mock.ExpectQuery(`FROM movies m WHERE EXISTS \( SELECT 1 FROM movie_people mp JOIN people p .+ \(p.name ILIKE \$1 OR p.name_en ILIKE \$2\)\) ORDER BY`).
+		WithArgs(`%100\%%`, `%100\%%`, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"movie_id"}))
+
+	_, err := repo.ListMovies(context.Background(), models.MovieFilter{PersonID: 7140}, 0, 20)
+	assert.NoError(t, err)
+	_, err = repo.ListMovies(context.Background(), models.MovieFilter{PersonName: "100%"}, 0, 20)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchPeople(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM people p WHERE p.name ILIKE`).
+		WithArgs("ривз").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT p.person_id, .+ AS movies_count FROM people p .+ ORDER BY .+ LIMIT \$2 OFFSET \$3`).
+		WithArgs("ривз", 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "name", "name_en", "photo_url", "movies_count"}).
+			AddRow(7140, "Киану Ривз", "Keanu Reeves", "", 4))
+
+	people, total, err := repo.SearchPeople(context.Background(), "ривз", 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []models.Person{{ID: 7140, Name: "Киану Ривз", NameEn: "Keanu Reeves", MoviesCount: 4}}, people)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideosFetchedAt(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	fetchedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT reviews_fetched_at FROM movies WHERE movie_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"reviews_fetched_at"}).AddRow(nil))
+	mock.ExpectQuery(`SELECT trailers_fetched_at FROM movies WHERE movie_id = \$1`).
+		WithArgs(int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"trailers_fetched_at"}).AddRow(fetchedAt))
+
+	at, err := repo.VideosFetchedAt(context.Background(), 1, VideoGroupReviews)
+	assert.NoError(t, err)
+	assert.Nil(t, at)
+
+	at, err = repo.VideosFetchedAt(context.Background(), 301, VideoGroupTrailers)
+	assert.NoError(t, err)
+	if assert.NotNil(t, at) {
+		assert.True(t, at.Equal(fetchedAt))
+	}
+
+	_, err = repo.VideosFetchedAt(context.Background(), 301, "clips")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMovieVideos(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	published := time.Date(2019, 3, 31, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM movie_videos WHERE movie_id = \$1 AND kind = ANY\(\$2\) ORDER BY CASE kind`).
+		WithArgs(int64(301), pq.Array([]string{"trailer", "teaser"})).
+		WillReturnRows(sqlmock.NewRows([]string{"video_id", "video_url", "title", "channel_title", "thumbnail_url",
+			"kind", "source", "language", "duration", "published_at"}).
+			AddRow("vKQi3bBA1y8", "https://www.youtube.com/watch?v=vKQi3bBA1y8", "Трейлер", "", "", "trailer", "kinopoisk", "ru", 0, nil).
+			AddRow("abc", "https://www.youtube.com/watch?v=abc", "Матрица — тизер", "Студия", "", "teaser", "youtube", "", 65, published))
+
+	videos, err := repo.GetMovieVideos(context.Background(), 301, []string{"trailer", "teaser"})
+	assert.NoError(t, err)
+	if assert.Len(t, videos, 2) {
+		assert.Equal(t, "kinopoisk", videos[0].Source)
+		assert.Nil(t, videos[0].PublishedAt)
+		assert.Equal(t, 65, videos[1].Duration)
+		if assert.NotNil(t, videos[1].PublishedAt) {
+			assert.True(t, videos[1].PublishedAt.Equal(published))
+		}
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceMovieVideos(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	videos := []models.Video{
+		{VideoID: "abc", VideoURL: "https://www.youtube.com/watch?v=abc", Title: "Обзор", ChannelTitle: "Кинокритик", Kind: "review", Source: "youtube", Duration: 600},
+		{VideoID: "def", VideoURL: "https://www.youtube.com/watch?v=def", Title: "Разбор", Kind: "review", Source: "youtube", Language: "ru"},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM movie_videos WHERE movie_id = \$1 AND kind = ANY\(\$2\)`).
+		WithArgs(int64(301), pq.Array([]string{"review"})).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	for i, v := range videos {
+		mock.ExpectExec(`INSERT INTO movie_videos \(movie_id, video_id, .+, position\)`).
+			WithArgs(int64(301), v.VideoID, v.VideoURL, v.Title, v.ChannelTitle, v.ThumbnailURL,
+				v.Kind, v.Source, v.Language, v.Duration, v.PublishedAt, i).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`UPDATE movies SET reviews_fetched_at = NOW\(\) WHERE movie_id = \$1`).
+		WithArgs(int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.ReplaceMovieVideos(context.Background(), 301, VideoGroupReviews, videos))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var userReviewCols = []string{"user_id", "movie_id", "rating", "review_title", "review_text",
+	"review_spoiler", "review_status", "reviewed_at", "helpful", "unhelpful"}
+
+func TestListUserReviews(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repo := &Repo{db: sqlxDB}
+
+	reviewedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM ratings WHERE movie_id = \$1 AND review_text IS NOT NULL AND review_status = 'published'`).
+		WithArgs(int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+	mock.ExpectQuery(`FROM ratings r WHERE r.movie_id = \$1 .+ ORDER BY r.helpful - r.unhelpful DESC, r.helpful DESC, r.reviewed_at DESC, r.user_id LIMIT \$2 OFFSET \$3`).
+		WithArgs(int64(301), 10, 10).
+		WillReturnRows(sqlmock.NewRows(userReviewCols).
+			AddRow(7, 301, 9, "Шедевр", "Пересматриваю каждый год.", true, "published", reviewedAt, 5, 1))
+
+	reviews, total, err := repo.ListUserReviews(context.Background(), 301, "unknown", 10, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, total)
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, int64(7), reviews[0].UserID)
+		assert.Equal(t, "Пересматриваю каждый год.", reviews[0].Text)
+		assert.True(t, reviews[0].Spoiler)
+		assert.Equal(t, 5, reviews[0].Helpful)
+		assert.Equal(t, 1, reviews[0].Unhelpful)
+		assert.True(t, reviews[0].ReviewedAt.Equal(reviewedAt))
+	}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM ratings`).
+		WithArgs(int64(302)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	reviews, total, err = repo.ListUserReviews(context.Background(), 302, ReviewsByNewest, 0, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, reviews)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserReview(t *testing.T) {
+	review := &models.UserReview{UserID: 7, MovieID: 301, Title: "Шедевр", Text: "Пересматриваю каждый год.", Spoiler: true}
+
+	tests := []struct {
+		name     string
+		rating   int
+		reviewed *bool // nil — оценки нет
+		hidden   bool
+		wantErr  error
+	}{
+		{"existing rating", 0, new(bool), false, nil},
+		{"with new rating", 9, new(bool), false, nil},
+		{"not rated", 0, nil, false, ErrNotRated},
+		{"already reviewed", 0, func() *bool { b := true; return &b }(), false, ErrDuplicate},
+		{"hidden by moderator", 9, new(bool), true, ErrReviewHidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, _ := sqlmock.New()
+			defer mockDB.Close()
+			repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+			mock.ExpectBegin()
+			if tt.rating > 0 {
+				mock.ExpectExec(`INSERT INTO ratings \(user_id, movie_id, rating\) VALUES \(\$1, \$2, \$3\) ON CONFLICT \(user_id, movie_id\) DO UPDATE`).
+					WithArgs(int64(7), int64(301), tt.rating).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			q := mock.ExpectQuery(`SELECT review_text IS NOT NULL FROM ratings WHERE user_id = \$1 AND movie_id = \$2 FOR UPDATE`).
+				WithArgs(int64(7), int64(301))
+			if tt.reviewed == nil {
+				q.WillReturnError(sql.ErrNoRows)
+			} else {
+				q.WillReturnRows(sqlmock.NewRows([]string{"reviewed"}).AddRow(*tt.reviewed))
+			}
+			if tt.reviewed != nil && !*tt.reviewed {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM hidden_reviews WHERE user_id = \$1 AND movie_id = \$2\)`).
+					WithArgs(int64(7), int64(301)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.hidden))
+			}
+			if tt.wantErr == nil {
+				mock.ExpectExec(`UPDATE ratings SET review_title = \$3, review_text = \$4, review_spoiler = \$5, review_status = 'published', reviewed_at = NOW\(\) WHERE user_id = \$1 AND movie_id = \$2`).
+					WithArgs(int64(7), int64(301), "Шедевр", "Пересматриваю каждый год.", true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			r := *review
+			r.Rating = tt.rating
+			assert.Equal(t, tt.wantErr, repo.CreateUserReview(context.Background(), &r))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteUserReview(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_title = '', review_text = NULL, .+ WHERE user_id = \$1 AND movie_id = \$2 AND review_text IS NOT NULL`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM review_votes WHERE author_id = \$1 AND movie_id = \$2`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DELETE FROM review_reports WHERE author_id = \$1 AND movie_id = \$2`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.DeleteUserReview(context.Background(), 7, 301))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_title = ''`).
+		WithArgs(int64(7), int64(302)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.DeleteUserReview(context.Background(), 7, 302), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVoteUserReview(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	vote := `INSERT INTO review_votes \(voter_id, author_id, movie_id, helpful\) SELECT \$1, user_id, movie_id, \$4 FROM \( SELECT user_id, movie_id FROM ratings WHERE user_id = \$2 AND movie_id = \$3 AND review_text IS NOT NULL AND review_status = 'published'\) review ON CONFLICT \(voter_id, author_id, movie_id\) DO UPDATE SET helpful = EXCLUDED.helpful`
+	mock.ExpectExec(vote).
+		WithArgs(int64(8), int64(7), int64(301), true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.VoteUserReview(context.Background(), 8, 7, 301, true))
+
+	// скрытая или удалённая рецензия
+	mock.ExpectExec(vote).
+		WithArgs(int64(8), int64(7), int64(302), false).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.VoteUserReview(context.Background(), 8, 7, 302, false), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportUserReview(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	report := `WITH review AS \(.+\), report AS \( INSERT INTO review_reports \(reporter_id, author_id, movie_id, reason\) SELECT \$1, user_id, movie_id, \$4 FROM review ON CONFLICT DO NOTHING\) SELECT COUNT\(\*\) FROM review`
+	mock.ExpectQuery(report).
+		WithArgs(int64(8), int64(7), int64(301), "спойлер без метки").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	assert.NoError(t, repo.ReportUserReview(context.Background(), 8, 7, 301, "спойлер без метки"))
+
+	mock.ExpectQuery(report).
+		WithArgs(int64(8), int64(7), int64(302), "").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	assert.ErrorIs(t, repo.ReportUserReview(context.Background(), 8, 7, 302, ""), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListModerationReviews(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	reportedAt := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM ratings r LEFT JOIN .+ FROM review_reports WHERE resolved_at IS NULL .+ WHERE r.review_text IS NOT NULL AND r.review_status = \$1`).
+		WithArgs("published").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`COALESCE\(rep.reports, 0\) AS reports, rep.last_reported_at, rep.reasons .+ ORDER BY reports DESC, rep.last_reported_at, r.movie_id, r.user_id LIMIT \$2 OFFSET \$3`).
+		WithArgs("published", 20, 0).
+		WillReturnRows(sqlmock.NewRows(append(userReviewCols, "reports", "last_reported_at", "reasons")).
+			AddRow(7, 301, 2, "", "Спойлер!", false, "published", reportedAt, 0, 4, 3, reportedAt, "{спойлер,оскорбления}").
+			AddRow(9, 302, 5, "", "Реклама", false, "published", reportedAt, 0, 0, 1, reportedAt, nil))
+
+	reviews, total, err := repo.ListModerationReviews(context.Background(), models.ReviewPublished, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	if assert.Len(t, reviews, 2) {
+		assert.Equal(t, int64(7), reviews[0].UserID)
+		assert.Equal(t, 3, reviews[0].Reports)
+		assert.Equal(t, 4, reviews[0].Unhelpful)
+		assert.Equal(t, []string{"спойлер", "оскорбления"}, reviews[0].Reasons)
+		assert.Equal(t, []string{}, reviews[1].Reasons)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHiddenReview_StaysHidden(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+	ctx := context.Background()
+
+	// модератор скрывает рецензию
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_status = \$3`).
+		WithArgs(int64(7), int64(301), "hidden").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO hidden_reviews`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SetUserReviewStatus(ctx, 7, 301, models.ReviewHidden))
+
+	// автор удаляет рецензию и пишет её заново
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_title = ''`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM review_votes`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM review_reports`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.DeleteUserReview(ctx, 7, 301))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT review_text IS NOT NULL FROM ratings`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewed"}).AddRow(false))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM hidden_reviews`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	assert.Equal(t, ErrReviewHidden, repo.CreateUserReview(ctx, &models.UserReview{UserID: 7, MovieID: 301, Text: "Снова опубликую"}))
+
+	// то же после удаления оценки
+	mock.ExpectExec(`DELETE FROM ratings WHERE user_id = \$1 AND movie_id = \$2`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteRating(ctx, 7, 301))
+
+	// запись о скрытии осталась, рецензию с новой оценкой не принять
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO ratings`).
+		WithArgs(int64(7), int64(301), 8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT review_text IS NOT NULL FROM ratings`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewed"}).AddRow(false))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM hidden_reviews`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	review := &models.UserReview{UserID: 7, MovieID: 301, Rating: 8, Text: "Снова опубликую"}
+	assert.Equal(t, ErrReviewHidden, repo.CreateUserReview(ctx, review))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetUserReviewStatus(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+	repo := &Repo{db: sqlx.NewDb(mockDB, "sqlmock")}
+
+	// скрытие оставляет жалобы открытыми и запоминается отдельно от рецензии
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_status = \$3 WHERE user_id = \$1 AND movie_id = \$2 AND review_text IS NOT NULL`).
+		WithArgs(int64(7), int64(301), "hidden").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO hidden_reviews \(user_id, movie_id\) VALUES \(\$1, \$2\) ON CONFLICT DO NOTHING`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SetUserReviewStatus(context.Background(), 7, 301, models.ReviewHidden))
+
+	// возврат отклоняет жалобы
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_status = \$3`).
+		WithArgs(int64(7), int64(301), "published").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE review_reports SET resolved_at = NOW\(\) WHERE author_id = \$1 AND movie_id = \$2 AND resolved_at IS NULL`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM hidden_reviews WHERE user_id = \$1 AND movie_id = \$2`).
+		WithArgs(int64(7), int64(301)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SetUserReviewStatus(context.Background(), 7, 301, models.ReviewPublished))
+
+	// возврат снимает скрытие и с удалённой автором рецензии
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_status = \$3`).
+		WithArgs(int64(7), int64(303), "published").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE review_reports SET resolved_at = NOW\(\)`).
+		WithArgs(int64(7), int64(303)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM hidden_reviews`).
+		WithArgs(int64(7), int64(303)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SetUserReviewStatus(context.Background(), 7, 303, models.ReviewPublished))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_status = \$3`).
+		WithArgs(int64(7), int64(304), "published").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE review_reports SET resolved_at = NOW\(\)`).
+		WithArgs(int64(7), int64(304)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM hidden_reviews`).
+		WithArgs(int64(7), int64(304)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.SetUserReviewStatus(context.Background(), 7, 304, models.ReviewPublished), sql.ErrNoRows)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ratings SET review_status = \$3`).
+		WithArgs(int64(7), int64(302), "hidden").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.SetUserReviewStatus(context.Background(), 7, 302, models.ReviewHidden), sql.ErrNoRows)
+
+	assert.Error(t, repo.SetUserReviewStatus(context.Background(), 7, 301, "deleted"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
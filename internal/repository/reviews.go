@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/lib/pq"
+)
+
+// --- User reviews ---
+
+// ErrNotRated — рецензия без оценки: пользователь ещё не оценил фильм
+var ErrNotRated = errors.New("movie is not rated")
+
+// ErrReviewHidden — модератор скрыл рецензию автора на этот фильм
+var ErrReviewHidden = errors.New("review is hidden by a moderator")
+
+// Сортировки рецензий пользователей
+const (
+	ReviewsByHelpful = "helpful" // сначала с лучшим перевесом голосов «полезно»
+	ReviewsByNewest  = "newest"
+)
+
+var userReviewOrders = map[string]string{
+	ReviewsByHelpful: "r.helpful - r.unhelpful DESC, r.helpful DESC, r.reviewed_at DESC, r.user_id",
+	ReviewsByNewest:  "r.reviewed_at DESC, r.user_id",
+}
+
+// IsValidUserReviewSort сообщает, поддерживается ли сортировка рецензий
+func IsValidUserReviewSort(sort string) bool {
+	_, ok := userReviewOrders[sort]
+	return ok
+}
+
+// userReviewColumns — поля рецензии из строки ratings r вместе с голосами
+const userReviewColumns = `
+      r.user_id, r.movie_id, r.rating, r.review_title, r.review_text,
+      r.review_spoiler, r.review_status, r.reviewed_at,
+      (SELECT COUNT(*) FROM review_votes v
+       WHERE v.author_id = r.user_id AND v.movie_id = r.movie_id AND v.helpful) AS helpful,
+      (SELECT COUNT(*) FROM review_votes v
+       WHERE v.author_id = r.user_id AND v.movie_id = r.movie_id AND NOT v.helpful) AS unhelpful`
+
+// ListUserReviews возвращает опубликованные рецензии пользователей на фильм
+// и их общее число; неизвестная сортировка — ReviewsByHelpful
+func (r *Repo) ListUserReviews(ctx context.Context, movieID int64, sort string, offset, limit int) ([]models.UserReview, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	order, ok := userReviewOrders[sort]
+	if !ok {
+		order = userReviewOrders[ReviewsByHelpful]
+	}
+	var total int
+	if err := r.db.GetContext(ctx, &total, `
+		SELECT COUNT(*) FROM ratings
+		WHERE movie_id = $1 AND review_text IS NOT NULL AND review_status = 'published'`, movieID); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	var reviews []models.UserReview
+	err := r.db.SelectContext(ctx, &reviews, `
+		SELECT * FROM (
+		  SELECT `+userReviewColumns+`
+		  FROM ratings r
+		  WHERE r.movie_id = $1 AND r.review_text IS NOT NULL AND r.review_status = 'published'
+		) r
+		ORDER BY `+order+`
+		LIMIT $2 OFFSET $3`, movieID, limit, offset)
+	return reviews, total, err
+}
+
+// GetUserReview возвращает рецензию пользователя на фильм в любом статусе;
+// sql.ErrNoRows, если рецензии нет
+func (r *Repo) GetUserReview(ctx context.Context, userID, movieID int64) (*models.UserReview, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var review models.UserReview
+	err := r.db.GetContext(ctx, &review, `SELECT `+userReviewColumns+`
+		FROM ratings r
+		WHERE r.user_id = $1 AND r.movie_id = $2 AND r.review_text IS NOT NULL`, userID, movieID)
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// CreateUserReview добавляет рецензию к оценке пользователя. Rating > 0
+// заодно ставит или меняет оценку; без оценки рецензию не к чему привязать
+// (ErrNotRated). ErrDuplicate — рецензия уже написана, ErrReviewHidden —
+// прежнюю рецензию скрыл модератор, даже если автор её потом удалил.
+func (r *Repo) CreateUserReview(ctx context.Context, review *models.UserReview) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if review.Rating > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO ratings (user_id, movie_id, rating) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, movie_id) DO UPDATE SET rating = $3, rated_at = NOW()`,
+			review.UserID, review.MovieID, review.Rating); err != nil {
+			return err
+		}
+	}
+	var reviewed bool
+	err = tx.GetContext(ctx, &reviewed, `
+		SELECT review_text IS NOT NULL FROM ratings
+		WHERE user_id = $1 AND movie_id = $2 FOR UPDATE`, review.UserID, review.MovieID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotRated
+	case err != nil:
+		return err
+	case reviewed:
+		return ErrDuplicate
+	}
+	var hidden bool
+	if err := tx.GetContext(ctx, &hidden, `
+		SELECT EXISTS (SELECT 1 FROM hidden_reviews WHERE user_id = $1 AND movie_id = $2)`,
+		review.UserID, review.MovieID); err != nil {
+		return err
+	}
+	if hidden {
+		return ErrReviewHidden
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE ratings
+		SET review_title = $3, review_text = $4, review_spoiler = $5,
+		    review_status = 'published', reviewed_at = NOW()
+		WHERE user_id = $1 AND movie_id = $2`,
+		review.UserID, review.MovieID, review.Title, review.Text, review.Spoiler); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateUserReview правит текст рецензии; Rating > 0 заодно меняет оценку.
+// Голоса и статус модерации сохраняются. sql.ErrNoRows, если рецензии нет.
+func (r *Repo) UpdateUserReview(ctx context.Context, review *models.UserReview) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE ratings
+		SET rating   = CASE WHEN $3 > 0 THEN $3 ELSE rating END,
+		    rated_at = CASE WHEN $3 > 0 THEN NOW() ELSE rated_at END,
+		    review_title = $4, review_text = $5, review_spoiler = $6
+		WHERE user_id = $1 AND movie_id = $2 AND review_text IS NOT NULL`,
+		review.UserID, review.MovieID, review.Rating, review.Title, review.Text, review.Spoiler)
+	return expectAffected(res, err)
+}
+
+// DeleteUserReview удаляет рецензию вместе с голосами и жалобами; оценка
+// остаётся. sql.ErrNoRows, если рецензии нет.
+func (r *Repo) DeleteUserReview(ctx context.Context, userID, movieID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE ratings
+		SET review_title = '', review_text = NULL, review_spoiler = FALSE,
+		    review_status = 'published', reviewed_at = NULL
+		WHERE user_id = $1 AND movie_id = $2 AND review_text IS NOT NULL`, userID, movieID)
+	if err := expectAffected(res, err); err != nil {
+		return err
+	}
+	for _, table := range []string{"review_votes", "review_reports"} {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM `+table+` WHERE author_id = $1 AND movie_id = $2`, userID, movieID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// publishedReview выбирает опубликованную рецензию автора $2 на фильм $3
+const publishedReview = `
+      SELECT user_id, movie_id FROM ratings
+      WHERE user_id = $2 AND movie_id = $3 AND review_text IS NOT NULL AND review_status = 'published'`
+
+// VoteUserReview ставит или меняет голос «полезно / бесполезно» за
+// опубликованную рецензию; sql.ErrNoRows, если такой рецензии нет
+func (r *Repo) VoteUserReview(ctx context.Context, voterID, authorID, movieID int64, helpful bool) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO review_votes (voter_id, author_id, movie_id, helpful)
+		SELECT $1, user_id, movie_id, $4 FROM (`+publishedReview+`) review
+		ON CONFLICT (voter_id, author_id, movie_id) DO UPDATE SET helpful = EXCLUDED.helpful, voted_at = NOW()`,
+		voterID, authorID, movieID, helpful)
+	return expectAffected(res, err)
+}
+
+// DeleteReviewVote отзывает голос за рецензию
+func (r *Repo) DeleteReviewVote(ctx context.Context, voterID, authorID, movieID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM review_votes WHERE voter_id = $1 AND author_id = $2 AND movie_id = $3`,
+		voterID, authorID, movieID)
+	return err
+}
+
+// ReportUserReview отправляет рецензию в очередь модерации. Повторная жалоба
+// того же пользователя ничего не меняет, в том числе после решения
+// модератора. sql.ErrNoRows, если опубликованной рецензии нет.
+func (r *Repo) ReportUserReview(ctx context.Context, reporterID, authorID, movieID int64, reason string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var found int
+	err := r.db.GetContext(ctx, &found, `
+		WITH review AS (`+publishedReview+`),
+		report AS (
+		  INSERT INTO review_reports (reporter_id, author_id, movie_id, reason)
+		  SELECT $1, user_id, movie_id, $4 FROM review
+		  ON CONFLICT DO NOTHING)
+		SELECT COUNT(*) FROM review`, reporterID, authorID, movieID, reason)
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// moderationFrom — рецензии со сводкой открытых жалоб; $1 — статус рецензии.
+// Опубликованные попадают в выборку, только если на них жаловались.
+const moderationFrom = `
+      FROM ratings r
+      LEFT JOIN (
+        SELECT author_id, movie_id, COUNT(*) AS reports, MAX(reported_at) AS last_reported_at,
+               array_remove(array_agg(reason ORDER BY reported_at), '') AS reasons
+        FROM review_reports WHERE resolved_at IS NULL
+        GROUP BY author_id, movie_id
+      ) rep ON rep.author_id = r.user_id AND rep.movie_id = r.movie_id
+      WHERE r.review_text IS NOT NULL AND r.review_status = $1
+        AND (r.review_status = 'hidden' OR rep.reports > 0)`
+
+// ListModerationReviews возвращает очередь модерации: опубликованные рецензии
+// с жалобами (status = published) или скрытые (hidden); сначала рецензии
+// с наибольшим числом жалоб
+func (r *Repo) ListModerationReviews(ctx context.Context, status string, offset, limit int) ([]models.ReportedReview, int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*)`+moderationFrom, status); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	var rows []struct {
+		models.ReportedReview
+		Reasons pq.StringArray `db:"reasons"`
+	}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+userReviewColumns+`,
+		       COALESCE(rep.reports, 0) AS reports, rep.last_reported_at, rep.reasons`+moderationFrom+`
+		ORDER BY reports DESC, rep.last_reported_at, r.movie_id, r.user_id
+		LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	reviews := make([]models.ReportedReview, len(rows))
+	for i, row := range rows {
+		reviews[i] = row.ReportedReview
+		reviews[i].Reasons = []string(row.Reasons)
+		if reviews[i].Reasons == nil {
+			reviews[i].Reasons = []string{}
+		}
+	}
+	return reviews, total, nil
+}
+
+// SetUserReviewStatus скрывает или возвращает рецензию. Возврат отклоняет
+// открытые жалобы, и рецензия уходит из очереди; у скрытой жалобы остаются
+// как объяснение. Скрытие запоминается в hidden_reviews, возврат снимает его,
+// даже если автор уже удалил рецензию. sql.ErrNoRows, если рецензии нет.
+func (r *Repo) SetUserReviewStatus(ctx context.Context, authorID, movieID int64, status string) error {
+	switch status {
+	case models.ReviewPublished, models.ReviewHidden:
+	default:
+		return fmt.Errorf("unknown review status %q", status)
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE ratings SET review_status = $3
+		WHERE user_id = $1 AND movie_id = $2 AND review_text IS NOT NULL`, authorID, movieID, status)
+	if status == models.ReviewHidden {
+		if err := expectAffected(res, err); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO hidden_reviews (user_id, movie_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, authorID, movieID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE review_reports SET resolved_at = NOW()
+		WHERE author_id = $1 AND movie_id = $2 AND resolved_at IS NULL`, authorID, movieID); err != nil {
+		return err
+	}
+	res, err = tx.ExecContext(ctx,
+		`DELETE FROM hidden_reviews WHERE user_id = $1 AND movie_id = $2`, authorID, movieID)
+	if err != nil {
+		return err
+	}
+	unhidden, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 && unhidden == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// --- Catalog sync ---
+
+// staleMovies — условие «карточка фильма устарела»; $1 — TTL в секундах
+const staleMovies = `(last_sync IS NULL OR last_sync < NOW() - make_interval(secs => $1))`
+
+// ListStaleMovies возвращает ID фильмов, не обновлявшихся дольше ttl. Первыми
+// идут фильмы из чьих-то списков «Смотреть позже» или оценок, затем — давно
+// не обновлявшиеся.
+func (r *Repo) ListStaleMovies(ctx context.Context, ttl time.Duration, limit int) ([]int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, `
+		SELECT m.movie_id
+		FROM movies m
+		WHERE `+staleMovies+`
+		ORDER BY (EXISTS (SELECT 1 FROM watchlist w WHERE w.movie_id = m.movie_id)
+		       OR EXISTS (SELECT 1 FROM ratings r WHERE r.movie_id = m.movie_id)) DESC,
+		         m.last_sync NULLS FIRST, m.movie_id
+		LIMIT $2`, ttl.Seconds(), limit)
+	return ids, err
+}
+
+// CountStaleMovies возвращает число фильмов, не обновлявшихся дольше ttl
+func (r *Repo) CountStaleMovies(ctx context.Context, ttl time.Duration) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var n int
+	err := r.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM movies WHERE `+staleMovies, ttl.Seconds())
+	return n, err
+}
+
+// TouchMovie отмечает фильм обновлённым, не меняя данных (например, если
+// Kinopoisk его больше не отдаёт), чтобы не запрашивать его каждый проход
+func (r *Repo) TouchMovie(ctx context.Context, id int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `UPDATE movies SET last_sync = NOW() WHERE movie_id = $1`, id)
+	return err
+}
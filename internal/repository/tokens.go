@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- Auth sessions & tokens ---
+
+// CreateAuthSession заводит сессию входа и её первый refresh-токен
+func (r *Repo) CreateAuthSession(ctx context.Context, sessionID string, userID int64, tokenHash string, ttl time.Duration) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO auth_sessions (session_id, user_id) VALUES ($1, $2)`,
+		sessionID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, session_id, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')`,
+		tokenHash, sessionID, int64(ttl.Seconds())); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetRefreshToken ищет refresh-токен по хэшу
+func (r *Repo) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var t models.RefreshToken
+	err := r.db.GetContext(ctx, &t, `
+		SELECT rt.token_hash, rt.session_id, s.user_id,
+		       rt.expires_at <= CURRENT_TIMESTAMP AS expired,
+		       rt.used_at IS NOT NULL AS used,
+		       s.revoked_at IS NOT NULL AS session_revoked,
+		       u.role, u.disabled_at IS NOT NULL AS user_disabled
+		FROM refresh_tokens rt
+		JOIN auth_sessions s ON s.session_id = rt.session_id
+		JOIN users u ON u.user_id = s.user_id
+		WHERE rt.token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RotateRefreshToken помечает старый токен использованным и выпускает новый
+// в той же сессии. Возвращает false, если старый токен уже был использован
+// (например, параллельным запросом).
+func (r *Repo) RotateRefreshToken(ctx context.Context, oldHash, newHash, sessionID string, ttl time.Duration) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE token_hash = $1 AND used_at IS NULL`,
+		oldHash)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, session_id, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')`,
+		newHash, sessionID, int64(ttl.Seconds())); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RevokeAuthSession отзывает сессию пользователя вместе со всеми её refresh-токенами
+func (r *Repo) RevokeAuthSession(ctx context.Context, userID int64, sessionID string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE auth_sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID)
+	return err
+}
+
+// RevokeAllAuthSessions отзывает все сессии пользователя
+func (r *Repo) RevokeAllAuthSessions(ctx context.Context, userID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE auth_sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
+
+// RevokeAccessToken добавляет jti в denylist до истечения токена
+// и заодно чистит записи об уже истёкших токенах
+func (r *Repo) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, CURRENT_TIMESTAMP + $2 * INTERVAL '1 second')
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, int64(time.Until(expiresAt).Seconds())+1)
+	return err
+}
+
+// IsAccessTokenRevoked проверяет jti по denylist и состояние сессии токена
+func (r *Repo) IsAccessTokenRevoked(ctx context.Context, jti, sessionID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var revoked bool
+	err := r.db.GetContext(ctx, &revoked, `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+		    OR EXISTS (SELECT 1 FROM auth_sessions WHERE session_id = $2 AND revoked_at IS NOT NULL)`,
+		jti, sessionID)
+	return revoked, err
+}
+
+// --- One-time user tokens ---
+
+// CreateUserToken сохраняет одноразовый токен; прежние неиспользованные
+// токены того же назначения перестают действовать
+func (r *Repo) CreateUserToken(ctx context.Context, tokenHash string, userID int64, purpose, email string, ttl time.Duration) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		userID, purpose); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO user_tokens (token_hash, user_id, purpose, email, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second')`,
+		tokenHash, userID, purpose, email, int64(ttl.Seconds())); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ConsumeUserToken гасит действующий токен; sql.ErrNoRows, если токен
+// неизвестен, истёк или уже использован
+func (r *Repo) ConsumeUserToken(ctx context.Context, tokenHash, purpose string) (*models.UserToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var t models.UserToken
+	err := r.db.GetContext(ctx, &t, `
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND purpose = $2
+		  AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id, email`, tokenHash, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SetVerifiedEmail записывает подтверждённый email пользователя
+func (r *Repo) SetVerifiedEmail(ctx context.Context, userID int64, email string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET email = $1, email_verified_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2`, email, userID)
+	return expectAffected(res, err)
+}
+
+// UpdatePassword меняет хэш пароля пользователя
+func (r *Repo) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE user_id = $2`, passwordHash, userID)
+	return expectAffected(res, err)
+}
+
+// ChangePassword меняет хэш пароля и отзывает все сессии пользователя, кроме
+// keepSessionID — сессии, из которой пароль сменили
+func (r *Repo) ChangePassword(ctx context.Context, userID int64, passwordHash, keepSessionID string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE user_id = $2`, passwordHash, userID)
+	if err := expectAffected(res, err); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE auth_sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND session_id <> $2 AND revoked_at IS NULL`,
+		userID, keepSessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/lib/pq"
+)
+
+// --- Videos ---
+
+// Группы видео фильма. Группа загружается из своих источников и заменяется
+// целиком, время её загрузки хранится в movies.
+const (
+	VideoGroupReviews  = "reviews"  // обзоры с YouTube
+	VideoGroupTrailers = "trailers" // трейлеры и тизеры из Kinopoisk и YouTube
+)
+
+type videoGroup struct {
+	fetchedAtColumn string
+	kinds           []string
+}
+
+var videoGroups = map[string]videoGroup{
+	VideoGroupReviews:  {"reviews_fetched_at", []string{models.VideoReview}},
+	VideoGroupTrailers: {"trailers_fetched_at", []string{models.VideoTrailer, models.VideoTeaser}},
+}
+
+func lookupVideoGroup(group string) (videoGroup, error) {
+	g, ok := videoGroups[group]
+	if !ok {
+		return g, fmt.Errorf("unknown video group %q", group)
+	}
+	return g, nil
+}
+
+// VideosFetchedAt возвращает время загрузки группы видео фильма; nil — группа
+// ещё не загружалась (sql.ErrNoRows, если фильма нет)
+func (r *Repo) VideosFetchedAt(ctx context.Context, movieID int64, group string) (*time.Time, error) {
+	g, err := lookupVideoGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var fetchedAt *time.Time
+	err = r.db.GetContext(ctx, &fetchedAt, `SELECT `+g.fetchedAtColumn+` FROM movies WHERE movie_id = $1`, movieID)
+	return fetchedAt, err
+}
+
+// GetMovieVideos возвращает сохранённые видео фильма перечисленных видов:
+// сначала трейлеры, затем тизеры и обзоры, внутри вида — в порядке загрузки
+func (r *Repo) GetMovieVideos(ctx context.Context, movieID int64, kinds []string) ([]models.Video, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var videos []models.Video
+	err := r.db.SelectContext(ctx, &videos, `
+		SELECT video_id, video_url, title, channel_title, thumbnail_url, kind, source, language, duration, published_at
+		FROM movie_videos WHERE movie_id = $1 AND kind = ANY($2)
+		ORDER BY CASE kind WHEN 'trailer' THEN 0 WHEN 'teaser' THEN 1 ELSE 2 END, position, video_id`,
+		movieID, pq.Array(kinds))
+	return videos, err
+}
+
+// ReplaceMovieVideos заменяет видео группы свежей выдачей и отмечает время
+// загрузки. Видео, уже сохранённое у фильма в другой группе, пропускается.
+func (r *Repo) ReplaceMovieVideos(ctx context.Context, movieID int64, group string, videos []models.Video) error {
+	g, err := lookupVideoGroup(group)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM movie_videos WHERE movie_id = $1 AND kind = ANY($2)`, movieID, pq.Array(g.kinds)); err != nil {
+		return err
+	}
+	for i, v := range videos {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO movie_videos (movie_id, video_id, video_url, title, channel_title, thumbnail_url,
+			                          kind, source, language, duration, published_at, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT DO NOTHING`,
+			movieID, v.VideoID, v.VideoURL, v.Title, v.ChannelTitle, v.ThumbnailURL,
+			v.Kind, v.Source, v.Language, v.Duration, v.PublishedAt, i); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE movies SET `+g.fetchedAtColumn+` = NOW() WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
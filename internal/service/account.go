@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/AlexKeyyyy/movies-picker/pkg/mailer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// --- Email verification & password reset ---
+
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+var (
+	ErrInvalidEmail  = invalid("email", "must be a valid email address")
+	ErrEmailTaken    = newError(ErrConflict, "email is already in use")
+	ErrInvalidToken  = invalid("token", "is invalid, expired or already used")
+	ErrEmptyPassword = invalid("password", "must not be empty")
+)
+
+// normalizeEmail проверяет, что строка — голый адрес вида user@host
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if !validation.IsEmail(email) {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// ensureEmailFree проверяет, что email не занят другим пользователем
+func (s *Service) ensureEmailFree(ctx context.Context, email string, userID int64) error {
+	other, err := s.repo.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.ID != userID {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// sendVerification выдаёт токен подтверждения адреса email и отправляет его письмом
+func (s *Service) sendVerification(ctx context.Context, userID int64, email string) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateUserToken(ctx, hashToken(token), userID, models.TokenVerifyEmail, email, verifyEmailTTL); err != nil {
+		return err
+	}
+	return s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Подтвердите email в Movie Picker",
+		Body: fmt.Sprintf("Чтобы подтвердить адрес, перейдите по ссылке:\n%s\n\nСсылка действует 24 часа.",
+			s.link("/verify-email", token)),
+	})
+}
+
+// VerifyEmail подтверждает адрес по токену из письма
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	t, err := s.repo.ConsumeUserToken(ctx, hashToken(token), models.TokenVerifyEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	// за время ожидания адрес мог занять другой пользователь
+	if err := s.ensureEmailFree(ctx, t.Email, t.UserID); err != nil {
+		return err
+	}
+	return s.repo.SetVerifiedEmail(ctx, t.UserID, t.Email)
+}
+
+// RequestPasswordReset отправляет письмо со ссылкой для сброса пароля.
+// Для неизвестного адреса ничего не делает, чтобы не раскрывать, кто зарегистрирован.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		log.Printf("password reset requested for disabled user %d", user.ID)
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateUserToken(ctx, hashToken(token), user.ID, models.TokenResetPassword, user.Email, resetPasswordTTL); err != nil {
+		return err
+	}
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля в Movie Picker",
+		Body: fmt.Sprintf("Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует 1 час. Если вы не запрашивали сброс, просто проигнорируйте письмо.",
+			s.link("/reset-password", token)),
+	})
+}
+
+// ResetPassword задаёт новый пароль по токену из письма и завершает все сессии
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if newPassword == "" {
+		return ErrEmptyPassword
+	}
+	t, err := s.repo.ConsumeUserToken(ctx, hashToken(token), models.TokenResetPassword)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, t.UserID, string(hashed)); err != nil {
+		return err
+	}
+	return s.repo.RevokeAllAuthSessions(ctx, t.UserID)
+}
+
+func (s *Service) link(path, token string) string {
+	return strings.TrimRight(s.appURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
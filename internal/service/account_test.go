@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "user@example.com", want: "user@example.com"},
+		{in: "  user@example.com ", want: "user@example.com"},
+		{in: "", wantErr: true},
+		{in: "not-an-email", wantErr: true},
+		{in: "user@localhost", wantErr: true},
+		{in: "User <user@example.com>", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeEmail(tt.in)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidEmail, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestLink(t *testing.T) {
+	s := &Service{appURL: "http://localhost:5173/"}
+	assert.Equal(t, "http://localhost:5173/reset-password?token=a%2Bb", s.link("/reset-password", "a+b"))
+}
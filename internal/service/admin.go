@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- Admin ---
+
+var (
+	ErrInvalidRole      = invalid("role", "must be one of: user, moderator, admin")
+	ErrSelfModification = newError(ErrConflict, "cannot change own role or disable own account")
+	ErrInvalidMerge     = invalid("into", "cannot merge a movie into itself")
+)
+
+// ListUsers возвращает пользователей постранично
+func (s *Service) ListUsers(ctx context.Context, page, size int) (*models.UserList, error) {
+	page, size = normalizePage(page, size)
+	users, err := s.repo.ListUsers(ctx, (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	return &models.UserList{
+		Items:      users,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+	}, nil
+}
+
+// UpdateUserByAdmin меняет роль и/или блокирует пользователя. Заблокированный
+// пользователь теряет все сессии сразу, новая роль действует с ближайшего
+// обновления токенов.
+func (s *Service) UpdateUserByAdmin(ctx context.Context, actorID, userID int64, upd models.UserUpdate) (*models.User, error) {
+	if actorID == userID {
+		return nil, ErrSelfModification
+	}
+	if upd.Role != nil {
+		if !models.IsValidRole(*upd.Role) {
+			return nil, ErrInvalidRole
+		}
+		if err := s.repo.SetUserRole(ctx, userID, *upd.Role); err != nil {
+			return nil, notFound(err, "user not found")
+		}
+	}
+	if upd.Disabled != nil {
+		if err := s.repo.SetUserDisabled(ctx, userID, *upd.Disabled); err != nil {
+			return nil, notFound(err, "user not found")
+		}
+		if *upd.Disabled {
+			if err := s.repo.RevokeAllAuthSessions(ctx, userID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	user, err := s.repo.GetUserByID(ctx, userID)
+	return user, notFound(err, "user not found")
+}
+
+// EnsureAdmins выдаёт роль администратора пользователям из списка (ADMIN_EMAILS)
+func (s *Service) EnsureAdmins(ctx context.Context, emails []string) error {
+	return s.repo.SetRoleByEmails(ctx, emails, models.RoleAdmin)
+}
+
+// UpdateMovie правит карточку фильма вручную
+func (s *Service) UpdateMovie(ctx context.Context, id int64, p models.MoviePatch) (*models.Movie, error) {
+	if err := s.repo.UpdateMovieFields(ctx, id, p); err != nil {
+		return nil, notFound(err, "movie not found")
+	}
+	return s.repo.GetMovieByID(ctx, id)
+}
+
+// DeleteMovie удаляет фильм из каталога
+func (s *Service) DeleteMovie(ctx context.Context, id int64) error {
+	return notFound(s.repo.DeleteMovie(ctx, id), "movie not found")
+}
+
+// MergeMovies сливает дубль sourceID в targetID и возвращает итоговый фильм
+func (s *Service) MergeMovies(ctx context.Context, sourceID, targetID int64) (*models.Movie, error) {
+	if sourceID == targetID {
+		return nil, ErrInvalidMerge
+	}
+	if err := s.repo.MergeMovies(ctx, sourceID, targetID); err != nil {
+		return nil, notFound(err, "movie not found")
+	}
+	return s.repo.GetMovieByID(ctx, targetID)
+}
+
+// ResyncMovie заново загружает карточку фильма из Kinopoisk
+func (s *Service) ResyncMovie(ctx context.Context, id int64) (*models.Movie, error) {
+	if err := s.refreshMovie(ctx, id); err != nil {
+		var svcErr *Error
+		if err = kinopoiskError(err, "film not found in Kinopoisk"); errors.As(err, &svcErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("resync movie %d: %w", id, err)
+	}
+	return s.repo.GetMovieByID(ctx, id)
+}
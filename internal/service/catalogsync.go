@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// --- Catalog sync ---
+
+// SyncConfig — настройки фонового обновления каталога
+type SyncConfig struct {
+	Interval  time.Duration // пауза между проходами; 0 — обновление выключено
+	TTL       time.Duration // карточка старше TTL считается устаревшей
+	BatchSize int           // сколько фильмов обновлять за проход
+	// DailyBudget — сколько запросов к Kinopoisk процесс может сделать за сутки,
+	// прежде чем обновление остановится; 0 — без ограничения. Учитываются все
+	// запросы, включая поиск и загрузку фильмов пользователями.
+	DailyBudget int
+}
+
+// syncStore — каталог, который обновляет catalogSync (*repository.Repo)
+type syncStore interface {
+	ListStaleMovies(ctx context.Context, ttl time.Duration, limit int) ([]int64, error)
+	TouchMovie(ctx context.Context, id int64) error
+	UpsertMovie(ctx context.Context, m *models.Movie) error
+}
+
+// syncClient — источник карточек для catalogSync (*kinopoisk.Client)
+type syncClient interface {
+	GetFilm(ctx context.Context, id int64) (*kinopoisk.Film, error)
+	RequestsToday() int
+}
+
+// catalogSync обновляет устаревшие карточки фильмов в фоне
+type catalogSync struct {
+	store  syncStore
+	client syncClient
+	cfg    SyncConfig
+	ctx    context.Context // отменяется вместе с сервером
+
+	mu     sync.Mutex
+	status models.SyncStatus
+}
+
+// StartCatalogSync запускает фоновое обновление каталога, пока ctx не отменён
+func (s *Service) StartCatalogSync(ctx context.Context, cfg SyncConfig) {
+	cs := &catalogSync{store: s.repo, client: s.kpClient, cfg: cfg, ctx: ctx}
+	cs.status.Enabled = cfg.Interval > 0
+	cs.status.Interval = cfg.Interval.String()
+	cs.status.TTL = cfg.TTL.String()
+	cs.status.BudgetLimit = cfg.DailyBudget
+	s.syncer = cs
+	if cs.status.Enabled {
+		go cs.loop()
+	}
+}
+
+func (cs *catalogSync) loop() {
+	t := time.NewTicker(cs.cfg.Interval)
+	defer t.Stop()
+	for {
+		next := time.Now().Add(cs.cfg.Interval)
+		cs.mu.Lock()
+		cs.status.NextRunAt = &next
+		cs.mu.Unlock()
+
+		select {
+		case <-cs.ctx.Done():
+			return
+		case <-t.C:
+			if cs.begin() {
+				cs.run()
+			}
+		}
+	}
+}
+
+// begin отмечает начало прохода; false — проход уже идёт
+func (cs *catalogSync) begin() bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.status.Running {
+		return false
+	}
+	now := time.Now()
+	cs.status.Running = true
+	cs.status.StartedAt = &now
+	cs.status.LastRun = models.SyncRun{}
+	cs.status.LastError = ""
+	return true
+}
+
+// run обновляет одну пачку устаревших фильмов
+func (cs *catalogSync) run() {
+	var run models.SyncRun
+	var runErr error
+	defer func() {
+		now := time.Now()
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		cs.status.Running = false
+		cs.status.FinishedAt = &now
+		cs.status.Totals.Batch += run.Batch
+		cs.status.Totals.Checked += run.Checked
+		cs.status.Totals.Updated += run.Updated
+		cs.status.Totals.Missing += run.Missing
+		cs.status.Totals.Failed += run.Failed
+		if runErr != nil {
+			cs.status.LastError = runErr.Error()
+			log.Printf("catalog sync: %v", runErr)
+		}
+	}()
+
+	ids, err := cs.store.ListStaleMovies(cs.ctx, cs.cfg.TTL, cs.cfg.BatchSize)
+	if err != nil {
+		runErr = err
+		return
+	}
+	run.Batch = len(ids)
+	cs.progress(run)
+
+	for _, id := range ids {
+		if cs.ctx.Err() != nil {
+			return
+		}
+		if cs.cfg.DailyBudget > 0 && cs.client.RequestsToday() >= cs.cfg.DailyBudget {
+			run.BudgetExhausted = true
+			cs.progress(run)
+			return
+		}
+		run.Checked++
+		err := saveFilm(cs.ctx, cs.client, cs.store, id)
+		switch {
+		case errors.Is(err, kinopoisk.ErrQuotaExceeded):
+			run.Failed++
+			run.BudgetExhausted = true
+			cs.progress(run)
+			return
+		case errors.Is(err, kinopoisk.ErrUnauthorized):
+			run.Failed++
+			runErr = err
+			cs.progress(run)
+			return
+		case errors.Is(err, kinopoisk.ErrNotFound):
+			run.Missing++
+			if err := cs.store.TouchMovie(cs.ctx, id); err != nil {
+				log.Printf("catalog sync: touch movie %d: %v", id, err)
+			}
+		case err != nil:
+			run.Failed++
+			log.Printf("catalog sync: movie %d: %v", id, err)
+		default:
+			run.Updated++
+		}
+		cs.progress(run)
+	}
+}
+
+func (cs *catalogSync) progress(run models.SyncRun) {
+	cs.mu.Lock()
+	cs.status.LastRun = run
+	cs.mu.Unlock()
+}
+
+// SyncStatus возвращает состояние фонового обновления каталога
+func (s *Service) SyncStatus(ctx context.Context) (*models.SyncStatus, error) {
+	var st models.SyncStatus
+	if cs := s.syncer; cs != nil {
+		cs.mu.Lock()
+		st = cs.status
+		cs.mu.Unlock()
+	}
+	st.BudgetUsed = s.kpClient.RequestsToday()
+	if st.TTL != "" {
+		n, err := s.repo.CountStaleMovies(ctx, s.syncer.cfg.TTL)
+		if err != nil {
+			return nil, err
+		}
+		st.StaleMovies = n
+	}
+	return &st, nil
+}
+
+// ErrSyncRunning — проход обновления каталога уже идёт
+var ErrSyncRunning = newError(ErrConflict, "catalog sync is already running")
+
+// TriggerCatalogSync запускает внеочередной проход обновления в фоне
+func (s *Service) TriggerCatalogSync() error {
+	cs := s.syncer
+	if cs == nil {
+		return newError(ErrConflict, "catalog sync is not configured")
+	}
+	if !cs.begin() {
+		return ErrSyncRunning
+	}
+	go cs.run()
+	return nil
+}
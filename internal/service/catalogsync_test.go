@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogSync_SingleRun(t *testing.T) {
+	s := &Service{}
+	assert.ErrorIs(t, s.TriggerCatalogSync(), ErrConflict)
+
+	// с нулевым интервалом фоновый цикл не стартует, ручной запуск доступен
+	s.StartCatalogSync(context.Background(), SyncConfig{TTL: time.Hour})
+	assert.False(t, s.syncer.status.Enabled)
+	assert.True(t, s.syncer.begin())
+	assert.ErrorIs(t, s.TriggerCatalogSync(), ErrSyncRunning)
+}
+
+// stubSyncStore — каталог в памяти для catalogSync
+type stubSyncStore struct {
+	stale   []int64
+	listErr error
+	upserts []int64
+	touched []int64
+}
+
+func (s *stubSyncStore) ListStaleMovies(ctx context.Context, ttl time.Duration, limit int) ([]int64, error) {
+	return s.stale, s.listErr
+}
+
+func (s *stubSyncStore) TouchMovie(ctx context.Context, id int64) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func (s *stubSyncStore) UpsertMovie(ctx context.Context, m *models.Movie) error {
+	s.upserts = append(s.upserts, m.ID)
+	return nil
+}
+
+// stubSyncClient считает запросы и отвечает ошибками из errs
+type stubSyncClient struct {
+	requests int
+	errs     map[int64]error
+}
+
+func (c *stubSyncClient) GetFilm(ctx context.Context, id int64) (*kinopoisk.Film, error) {
+	c.requests++
+	if err := c.errs[id]; err != nil {
+		return nil, err
+	}
+	return &kinopoisk.Film{KinopoiskID: id}, nil
+}
+
+func (c *stubSyncClient) RequestsToday() int { return c.requests }
+
+func TestCatalogSync_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  int
+		listErr error
+		errs    map[int64]error
+		want    models.SyncRun
+		upserts []int64
+		touched []int64
+		lastErr string
+	}{
+		{
+			name:    "all updated",
+			budget:  10,
+			want:    models.SyncRun{Batch: 3, Checked: 3, Updated: 3},
+			upserts: []int64{1, 2, 3},
+		},
+		{
+			name:    "budget stops the run",
+			budget:  2,
+			want:    models.SyncRun{Batch: 3, Checked: 2, Updated: 2, BudgetExhausted: true},
+			upserts: []int64{1, 2},
+		},
+		{
+			name:    "zero budget means no limit",
+			budget:  0,
+			want:    models.SyncRun{Batch: 3, Checked: 3, Updated: 3},
+			upserts: []int64{1, 2, 3},
+		},
+		{
+			name:    "quota exceeded stops the run",
+			budget:  10,
+			errs:    map[int64]error{2: kinopoisk.ErrQuotaExceeded},
+			want:    models.SyncRun{Batch: 3, Checked: 2, Updated: 1, Failed: 1, BudgetExhausted: true},
+			upserts: []int64{1},
+		},
+		{
+			name:    "unauthorized aborts the run",
+			budget:  10,
+			errs:    map[int64]error{1: kinopoisk.ErrUnauthorized},
+			want:    models.SyncRun{Batch: 3, Checked: 1, Failed: 1},
+			lastErr: kinopoisk.ErrUnauthorized.Error(),
+		},
+		{
+			name:    "missing movie is touched",
+			budget:  10,
+			errs:    map[int64]error{2: kinopoisk.ErrNotFound},
+			want:    models.SyncRun{Batch: 3, Checked: 3, Updated: 2, Missing: 1},
+			upserts: []int64{1, 3},
+			touched: []int64{2},
+		},
+		{
+			name:    "other errors are skipped",
+			budget:  10,
+			errs:    map[int64]error{1: errors.New("boom")},
+			want:    models.SyncRun{Batch: 3, Checked: 3, Updated: 2, Failed: 1},
+			upserts: []int64{2, 3},
+		},
+		{
+			name:    "list error",
+			budget:  10,
+			listErr: errors.New("db down"),
+			lastErr: "db down",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &stubSyncStore{stale: []int64{1, 2, 3}, listErr: tt.listErr}
+			client := &stubSyncClient{errs: tt.errs}
+			cs := &catalogSync{
+				store:  store,
+				client: client,
+				cfg:    SyncConfig{TTL: time.Hour, BatchSize: 10, DailyBudget: tt.budget},
+				ctx:    context.Background(),
+			}
+
+			assert.True(t, cs.begin())
+			cs.run()
+
+			assert.False(t, cs.status.Running)
+			assert.Equal(t, tt.want, cs.status.LastRun)
+			assert.Equal(t, tt.lastErr, cs.status.LastError)
+			assert.Equal(t, tt.upserts, store.upserts)
+			assert.Equal(t, tt.touched, store.touched)
+
+			// в итоги попадают только счётчики прохода
+			want := tt.want
+			want.BudgetExhausted = false
+			assert.Equal(t, want, cs.status.Totals)
+		})
+	}
+}
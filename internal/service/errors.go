@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
+)
+
+// --- Errors ---
+
+// Категории ошибок сервиса; handlers переводят их в HTTP-статусы через errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnavailable  = errors.New("service unavailable") // внешний API недоступен или исчерпал лимит
+)
+
+// Error — ошибка сервиса одной из категорий. У ошибок валидации Fields
+// указывают, какие поля запроса неверны.
+type Error struct {
+	Kind   error
+	Detail string
+	Fields validation.Errors
+}
+
+func (e *Error) Error() string { return e.Detail }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+func newError(kind error, detail string) error {
+	return &Error{Kind: kind, Detail: detail}
+}
+
+// invalid — ошибка валидации одного поля
+func invalid(field, message string) error {
+	return &Error{
+		Kind:   ErrValidation,
+		Detail: field + ": " + message,
+		Fields: validation.Errors{{Field: field, Message: message}},
+	}
+}
+
+// notFound превращает sql.ErrNoRows из репозитория в ErrNotFound с описанием
+func notFound(err error, detail string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return newError(ErrNotFound, detail)
+	}
+	return err
+}
+
+// kinopoiskError переводит типизированные ошибки Kinopoisk в категории сервиса;
+// notFoundDetail — текст ответа, если Kinopoisk не знает фильма
+func kinopoiskError(err error, notFoundDetail string) error {
+	switch {
+	case errors.Is(err, kinopoisk.ErrNotFound):
+		return newError(ErrNotFound, notFoundDetail)
+	case errors.Is(err, kinopoisk.ErrQuotaExceeded), errors.Is(err, kinopoisk.ErrRateLimited):
+		return newError(ErrUnavailable, "movie database request limit reached, try again later")
+	case errors.Is(err, kinopoisk.ErrUnauthorized):
+		// ключ API неверен — это ошибка конфигурации сервера, клиенту детали не нужны
+		log.Printf("kinopoisk: %v", err)
+		return newError(ErrUnavailable, "movie database is unavailable")
+	}
+	return err
+}
+
+// youtubeError переводит ошибки источников видео в ответ 503: видео —
+// необязательная часть карточки, а квота восстановится к следующим суткам
+func youtubeError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(err, youtube.ErrQuotaExceeded) {
+		return newError(ErrUnavailable, "video search quota is exhausted, try again later")
+	}
+	log.Printf("videos: %v", err)
+	return newError(ErrUnavailable, "videos are temporarily unavailable")
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/validation"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{ErrEmailTaken, ErrConflict},
+		{ErrInvalidEmail, ErrValidation},
+		{ErrInvalidCredentials, ErrUnauthorized},
+		{ErrUserDisabled, ErrForbidden},
+		{ErrNoCandidates, ErrNotFound},
+		{ErrInvalidVideoKind, ErrValidation},
+		{fmt.Errorf("%w: a@b.ru", ErrUnknownUsers), ErrValidation},
+	}
+	for _, tt := range tests {
+		assert.ErrorIs(t, tt.err, tt.kind, tt.err.Error())
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	var svcErr *Error
+	assert.True(t, errors.As(ErrInvalidVote, &svcErr))
+	assert.Equal(t, validation.Errors{{Field: "kind", Message: "must be one of: up, down, veto"}}, svcErr.Fields)
+	assert.Equal(t, "kind: must be one of: up, down, veto", ErrInvalidVote.Error())
+}
+
+func TestNotFound(t *testing.T) {
+	err := notFound(sql.ErrNoRows, "movie not found")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "movie not found", err.Error())
+
+	other := errors.New("connection refused")
+	assert.Equal(t, other, notFound(other, "movie not found"))
+	assert.NoError(t, notFound(nil, "movie not found"))
+}
+
+func TestKinopoiskError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{&kinopoisk.StatusError{StatusCode: 404}, ErrNotFound},
+		{&kinopoisk.StatusError{StatusCode: 402}, ErrUnavailable},
+		{&kinopoisk.StatusError{StatusCode: 429}, ErrUnavailable},
+		{kinopoisk.ErrQuotaExceeded, ErrUnavailable},
+		{&kinopoisk.StatusError{StatusCode: 401}, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		assert.ErrorIs(t, kinopoiskError(tt.err, "movie not found"), tt.kind, tt.err.Error())
+	}
+
+	other := &kinopoisk.StatusError{StatusCode: 500}
+	assert.Equal(t, error(other), kinopoiskError(other, "movie not found"))
+}
+
+func TestYoutubeError(t *testing.T) {
+	quota := youtubeError(&youtube.StatusError{StatusCode: 403, Reason: "quotaExceeded"})
+	assert.ErrorIs(t, quota, ErrUnavailable)
+	assert.Equal(t, "video search quota is exhausted, try again later", quota.Error())
+
+	assert.ErrorIs(t, youtubeError(&youtube.StatusError{StatusCode: 500}), ErrUnavailable)
+	assert.Equal(t, context.DeadlineExceeded, youtubeError(context.DeadlineExceeded))
+}
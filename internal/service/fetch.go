@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// --- Lazy fetch ---
+
+// fetchGroup объединяет параллельные загрузки одного и того же ключа
+// (фильма, группы видео): пока идёт запрос к внешнему API, остальные вызовы
+// ждут его результата. Нулевое значение готово к работе.
+type fetchGroup[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*fetchCall[V]
+}
+
+type fetchCall[V any] struct {
+	done  chan struct{} // закрывается, когда fn вернулась
+	value V
+	err   error
+}
+
+// Do запускает fn для key, если такой вызов ещё не идёт, и ждёт результата.
+// fn выполняется в своей горутине и доводится до конца, даже если все
+// ожидающие ушли по отмене своего ctx: тогда Do возвращает ctx.Err().
+func (g *fetchGroup[K, V]) Do(ctx context.Context, key K, fn func() (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*fetchCall[V])
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		c = &fetchCall[V]{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.run(key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (g *fetchGroup[K, V]) run(key K, c *fetchCall[V], fn func() (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero V
+			c.value, c.err = zero, fmt.Errorf("fetch %v: panic: %v", key, r)
+			log.Print(c.err)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn()
+}
+
+// missingMovieTTL — сколько помнить ID, которых нет в Kinopoisk: без этого
+// каждый запрос выдуманного ID тратил бы суточную квоту ключа
+const missingMovieTTL = time.Hour
+
+// maxMissingMovies — сколько таких ID помнить
+const maxMissingMovies = 10000
+
+// missingMovies — ID фильмов, которых нет в Kinopoisk, и время проверки.
+// Нулевое значение готово к работе.
+type missingMovies struct {
+	mu  sync.Mutex
+	ids map[int64]time.Time
+}
+
+// has сообщает, что id недавно не нашёлся в Kinopoisk
+func (m *missingMovies) has(id int64, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	checked, ok := m.ids[id]
+	return ok && now.Sub(checked) < missingMovieTTL
+}
+
+// add запоминает id; когда места нет, сначала выбрасываются устаревшие
+// записи, затем самая старая
+func (m *missingMovies) add(id int64, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ids == nil {
+		m.ids = make(map[int64]time.Time)
+	}
+	if _, ok := m.ids[id]; !ok && len(m.ids) >= maxMissingMovies {
+		var oldest int64
+		for other, checked := range m.ids {
+			if now.Sub(checked) >= missingMovieTTL {
+				delete(m.ids, other)
+			} else if oldest == 0 || checked.Before(m.ids[oldest]) {
+				oldest = other
+			}
+		}
+		if len(m.ids) >= maxMissingMovies {
+			delete(m.ids, oldest)
+		}
+	}
+	m.ids[id] = now
+}
+
+// fetchMovie загружает фильм, которого нет в каталоге, из Kinopoisk и сохраняет его.
+// Результата ждут все параллельные запросы фильма, поэтому загрузка не
+// прерывается отменой первого из них; её ограничивает дедлайн клиента Kinopoisk.
+// ID, которых нет в Kinopoisk, запоминаются на missingMovieTTL. Удалённые
+// модератором фильмы не загружаются, а вместо слитого дубля возвращается
+// целевой фильм.
+func (s *Service) fetchMovie(ctx context.Context, id int64) (*models.Movie, error) {
+	if s.missing.has(id, time.Now()) {
+		return nil, newError(ErrNotFound, "movie not found")
+	}
+	switch target, err := s.repo.GetRemovedMovie(ctx, id); {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	case target == nil:
+		return nil, newError(ErrNotFound, "movie was removed")
+	default:
+		m, err := s.repo.GetMovieByID(ctx, *target)
+		return m, notFound(err, "movie not found")
+	}
+	fetchCtx := context.WithoutCancel(ctx)
+	return s.fetches.Do(ctx, id, func() (*models.Movie, error) {
+		if err := s.refreshMovie(fetchCtx, id); err != nil {
+			if errors.Is(err, kinopoisk.ErrNotFound) {
+				s.missing.add(id, time.Now())
+			}
+			return nil, kinopoiskError(err, "movie not found")
+		}
+		// состав не критичен: не загрузился — догрузится при запросе /cast
+		if err := s.refreshCast(fetchCtx, id); err != nil {
+			log.Printf("fetch cast of movie %d: %v", id, err)
+		}
+		return s.repo.GetMovieByID(fetchCtx, id)
+	})
+}
+
+// refreshMovie загружает карточку фильма из Kinopoisk и сохраняет её в каталог
+func (s *Service) refreshMovie(ctx context.Context, id int64) error {
+	return saveFilm(ctx, s.kpClient, s.repo, id)
+}
+
+// saveFilm загружает карточку фильма из client и сохраняет её в store
+func saveFilm(ctx context.Context, client syncClient, store syncStore, id int64) error {
+	film, err := client.GetFilm(ctx, id)
+	if err != nil {
+		return err
+	}
+	m := MovieFromFilm(*film)
+	m.ID = id
+	return store.UpsertMovie(ctx, &m)
+}
+
+// GetMovie возвращает фильм из каталога; неизвестный фильм прозрачно
+// загружается из Kinopoisk по его ID, а по ID слитого дубля возвращается
+// целевой фильм
+func (s *Service) GetMovie(ctx context.Context, id int64) (*models.Movie, error) {
+	m, err := s.repo.GetMovieByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.fetchMovie(ctx, id)
+	}
+	return m, err
+}
+
+// ensureMovie проверяет, что фильм есть в каталоге, до записи ссылок на него;
+// недостающий фильм загружается из Kinopoisk. Ссылки на слитый дубль не
+// записываются: клиент должен перейти на целевой фильм.
+func (s *Service) ensureMovie(ctx context.Context, id int64) error {
+	exists, err := s.repo.MovieExists(ctx, id)
+	if err != nil || exists {
+		return err
+	}
+	m, err := s.fetchMovie(ctx, id)
+	if err != nil {
+		return err
+	}
+	if m.ID != id {
+		return newError(ErrNotFound, fmt.Sprintf("movie was merged into %d", m.ID))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchGroup_Deduplicates(t *testing.T) {
+	var g fetchGroup[int64, *models.Movie]
+	var calls int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]*models.Movie, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = g.Do(context.Background(), 301, func() (*models.Movie, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return &models.Movie{ID: 301}, nil
+			})
+		}(i)
+	}
+	// даём горутинам встать в ожидание первого вызова
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, m := range results {
+		assert.Equal(t, int64(301), m.ID)
+	}
+
+	// после завершения следующий вызов снова идёт в fn
+	g.Do(context.Background(), 301, func() (*models.Movie, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil
+	})
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetchGroup_WaiterCanceled(t *testing.T) {
+	var g fetchGroup[int64, *models.Movie]
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.Do(context.Background(), 301, func() (*models.Movie, error) {
+			<-release
+			return &models.Movie{ID: 301}, nil
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := g.Do(ctx, 301, func() (*models.Movie, error) {
+		t.Error("Expected to wait for the running fetch")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	<-done
+}
+
+func TestFetchGroup_Panic(t *testing.T) {
+	var g fetchGroup[int64, *models.Movie]
+	_, err := g.Do(context.Background(), 301, func() (*models.Movie, error) {
+		panic("boom")
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "panic: boom")
+	}
+
+	// упавший вызов не блокирует следующие
+	m, err := g.Do(context.Background(), 301, func() (*models.Movie, error) {
+		return &models.Movie{ID: 301}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(301), m.ID)
+}
+
+func TestMissingMovies(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var m missingMovies
+	assert.False(t, m.has(999999999, now))
+
+	m.add(999999999, now)
+	assert.True(t, m.has(999999999, now.Add(missingMovieTTL-time.Minute)))
+	assert.False(t, m.has(999999999, now.Add(missingMovieTTL)))
+
+	for i := 1; i < maxMissingMovies; i++ {
+		m.add(int64(i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	m.add(0, now.Add(time.Second))
+	assert.Len(t, m.ids, maxMissingMovies)
+	assert.False(t, m.has(999999999, now), "the oldest entry is evicted")
+}
+
+func TestFetchMovie_RemembersMissing(t *testing.T) {
+	// ID из кэша отсутствующих не доходит ни до БД, ни до Kinopoisk
+	s := &Service{}
+	s.missing.add(999999999, time.Now())
+	_, err := s.fetchMovie(context.Background(), 999999999)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
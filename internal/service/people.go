@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// --- People ---
+
+// CastFromStaff переводит съёмочную группу Kinopoisk в состав фильма,
+// сохраняя порядок титров; участники без имени пропускаются
+func CastFromStaff(staff []kinopoisk.StaffMember) []models.CastMember {
+	cast := make([]models.CastMember, 0, len(staff))
+	for _, m := range staff {
+		if m.StaffID == 0 || m.Name() == "" {
+			continue
+		}
+		cast = append(cast, models.CastMember{
+			PersonID:   m.StaffID,
+			Name:       m.Name(),
+			NameEn:     m.NameEn,
+			PhotoURL:   m.PosterURL,
+			Profession: m.ProfessionKey,
+			Role:       m.Description,
+		})
+	}
+	return cast
+}
+
+// refreshCast загружает состав фильма из Kinopoisk. Фильм без данных о
+// съёмочной группе сохраняется с пустым составом, чтобы не запрашивать его снова.
+func (s *Service) refreshCast(ctx context.Context, movieID int64) error {
+	staff, err := s.kpClient.GetStaff(ctx, movieID)
+	if err != nil && !errors.Is(err, kinopoisk.ErrNotFound) {
+		return err
+	}
+	return s.repo.ReplaceMovieCast(ctx, movieID, CastFromStaff(staff))
+}
+
+// GetMovieCast возвращает актёров и съёмочную группу фильма; состав,
+// который ещё не загружался, запрашивается у Kinopoisk
+func (s *Service) GetMovieCast(ctx context.Context, movieID int64) ([]models.CastMember, error) {
+	if err := s.ensureMovie(ctx, movieID); err != nil {
+		return nil, err
+	}
+	synced, err := s.repo.MovieCastSynced(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if !synced {
+		if err := s.refreshCast(ctx, movieID); err != nil {
+			return nil, kinopoiskError(err, "movie not found")
+		}
+	}
+	cast, err := s.repo.GetMovieCast(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if cast == nil {
+		cast = []models.CastMember{}
+	}
+	return cast, nil
+}
+
+// GetPerson возвращает человека и его фильмы из каталога
+func (s *Service) GetPerson(ctx context.Context, id int64) (*models.PersonDetails, error) {
+	p, err := s.repo.GetPerson(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "person not found")
+	}
+	movies, err := s.repo.GetPersonMovies(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if movies == nil {
+		movies = []models.PersonMovie{}
+	}
+	return &models.PersonDetails{Person: *p, Movies: movies}, nil
+}
+
+// SearchPeople ищет людей по имени постранично
+func (s *Service) SearchPeople(ctx context.Context, query string, page, size int) (*models.PersonList, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, invalid("q", "is required")
+	}
+	page, size = normalizePage(page, size)
+	people, total, err := s.repo.SearchPeople(ctx, query, (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+	if people == nil {
+		people = []models.Person{}
+	}
+	return &models.PersonList{
+		Items:      people,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+	}, nil
+}
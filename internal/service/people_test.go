@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastFromStaff(t *testing.T) {
+	cast := CastFromStaff([]kinopoisk.StaffMember{
+		{StaffID: 7836, NameRu: "Лана Вачовски", ProfessionKey: kinopoisk.ProfessionDirector},
+		{StaffID: 0, NameRu: "Без ID", ProfessionKey: kinopoisk.ProfessionActor},
+		{StaffID: 7140, NameEn: "Keanu Reeves", Description: "Neo", PosterURL: "https://x/7140.jpg", ProfessionKey: kinopoisk.ProfessionActor},
+		{StaffID: 9999, ProfessionKey: kinopoisk.ProfessionActor},
+	})
+	assert.Equal(t, []models.CastMember{
+		{PersonID: 7836, Name: "Лана Вачовски", Profession: "DIRECTOR"},
+		{PersonID: 7140, Name: "Keanu Reeves", NameEn: "Keanu Reeves", PhotoURL: "https://x/7140.jpg", Profession: "ACTOR", Role: "Neo"},
+	}, cast)
+
+	assert.Empty(t, CastFromStaff(nil))
+}
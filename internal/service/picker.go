@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- Picker ---
+
+const (
+	// сколько лучших фильмов каталога участвуют в выборе, если «Смотреть позже» пуст
+	catalogPickPoolSize = 500
+	// за сколько дней в «Смотреть позже» вес фильма удваивается
+	watchlistAgeDoublingDays = 30.0
+	// максимальный множитель за «возраст» записи в «Смотреть позже»
+	maxWatchlistAgeBoost = 4.0
+)
+
+// ErrNoCandidates — ни один фильм не подходит под ограничения
+var ErrNoCandidates = newError(ErrNotFound, "no movies match the filters")
+
+// PickMovie выбирает один фильм на вечер из «Смотреть позже» пользователя
+// (или из каталога, если список пуст) с учётом фильтра. Для воспроизводимого
+// результата можно передать свой rng; nil — случайный выбор.
+func (s *Service) PickMovie(ctx context.Context, userID int64, f models.PickFilter, rng *rand.Rand) (*models.PickResult, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	watchlist, err := s.repo.GetWatchlist(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := "watchlist"
+	var candidates []models.PickCandidate
+	if len(watchlist) > 0 {
+		candidates, err = s.repo.GetWatchlistPickCandidates(ctx, userID, f)
+	} else {
+		source = "catalog"
+		candidates, err = s.repo.GetCatalogPickCandidates(ctx, f, catalogPickPoolSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	picked, ok := pickWeighted(candidates, time.Now(), rng)
+	if !ok {
+		return nil, ErrNoCandidates
+	}
+	return &models.PickResult{
+		PickCandidate: picked,
+		Source:        source,
+		Candidates:    len(candidates),
+	}, nil
+}
+
+// pickWeight — вес кандидата: квадрат рейтинга Кинопоиска, умноженный
+// на бонус за время ожидания в «Смотреть позже»
+func pickWeight(c models.PickCandidate, now time.Time) float64 {
+	rating := math.Max(c.RatingKinopoisk, 1)
+	w := rating * rating
+	if c.AddedAt != nil {
+		days := now.Sub(*c.AddedAt).Hours() / 24
+		if days > 0 {
+			w *= math.Min(1+days/watchlistAgeDoublingDays, maxWatchlistAgeBoost)
+		}
+	}
+	return w
+}
+
+// pickWeighted выбирает кандидата случайно пропорционально его весу
+func pickWeighted(candidates []models.PickCandidate, now time.Time, rng *rand.Rand) (models.PickCandidate, bool) {
+	if len(candidates) == 0 {
+		return models.PickCandidate{}, false
+	}
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, c := range candidates {
+		weights[i] = pickWeight(c, now)
+		total += weights[i]
+	}
+
+	x := rng.Float64() * total
+	for i, w := range weights {
+		x -= w
+		if x < 0 {
+			return candidates[i], true
+		}
+	}
+	return candidates[len(candidates)-1], true
+}
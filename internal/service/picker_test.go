@@ -0,0 +1,45 @@
+package service
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickWeighted_Reproducible(t *testing.T) {
+	now := time.Now()
+	candidates := []models.PickCandidate{
+		{Movie: models.Movie{ID: 1, RatingKinopoisk: 8.5}},
+		{Movie: models.Movie{ID: 2, RatingKinopoisk: 6.0}},
+		{Movie: models.Movie{ID: 3, RatingKinopoisk: 7.2}},
+	}
+
+	first, ok := pickWeighted(candidates, now, rand.New(rand.NewSource(42)))
+	assert.True(t, ok)
+	for i := 0; i < 5; i++ {
+		again, _ := pickWeighted(candidates, now, rand.New(rand.NewSource(42)))
+		assert.Equal(t, first.ID, again.ID)
+	}
+}
+
+func TestPickWeighted_Empty(t *testing.T) {
+	_, ok := pickWeighted(nil, time.Now(), rand.New(rand.NewSource(1)))
+	assert.False(t, ok)
+}
+
+func TestPickWeight(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-60 * 24 * time.Hour)
+	fresh := now
+
+	high := models.PickCandidate{Movie: models.Movie{RatingKinopoisk: 9}}
+	low := models.PickCandidate{Movie: models.Movie{RatingKinopoisk: 5}}
+	assert.Greater(t, pickWeight(high, now), pickWeight(low, now))
+
+	oldItem := models.PickCandidate{Movie: models.Movie{RatingKinopoisk: 7}, AddedAt: &old}
+	newItem := models.PickCandidate{Movie: models.Movie{RatingKinopoisk: 7}, AddedAt: &fresh}
+	assert.InDelta(t, 3*pickWeight(newItem, now), pickWeight(oldItem, now), 0.001)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- Recommendations ---
+
+const (
+	// оценка, начиная с которой фильм считается понравившимся
+	likedRatingThreshold = 7
+	// сглаживание сходства фильмов с малым числом общих оценок
+	similarityShrinkage = 5.0
+)
+
+// recCandidate — промежуточный результат item-based фильтрации
+type recCandidate struct {
+	MovieID   int64
+	Score     float64
+	Support   float64
+	BecauseID int64
+}
+
+// GetRecommendations строит персональную подборку на основе оценок пользователя
+// и оценок других пользователей (item-based collaborative filtering)
+func (s *Service) GetRecommendations(ctx context.Context, userID int64, limit int) ([]models.Recommendation, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	target, others, err := s.loadRatingMatrix(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(target) == 0 {
+		return []models.Recommendation{}, nil
+	}
+	watchlist, err := s.repo.GetWatchlist(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exclude := make(map[int64]bool, len(watchlist))
+	for _, w := range watchlist {
+		exclude[w.MovieID] = true
+	}
+
+	candidates := scoreItemBased(target, others, exclude)
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	if len(candidates) == 0 {
+		return []models.Recommendation{}, nil
+	}
+
+	ids := make([]int64, 0, len(candidates)*2)
+	for _, c := range candidates {
+		ids = append(ids, c.MovieID)
+		if c.BecauseID != 0 {
+			ids = append(ids, c.BecauseID)
+		}
+	}
+	movies, err := s.repo.GetMoviesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Movie, len(movies))
+	for _, m := range movies {
+		byID[m.ID] = m
+	}
+
+	out := make([]models.Recommendation, 0, len(candidates))
+	for _, c := range candidates {
+		m, ok := byID[c.MovieID]
+		if !ok {
+			continue
+		}
+		rec := models.Recommendation{
+			Movie: m,
+			Score: math.Round(c.Score*100) / 100,
+		}
+		if liked, ok := byID[c.BecauseID]; ok {
+			rec.BecauseMovieID = liked.ID
+			rec.Explanation = fmt.Sprintf("because you liked %s", liked.Title)
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// loadRatingMatrix возвращает оценки пользователя (фильм -> оценка) и оценки
+// его «соседей» (пользователь -> фильм -> оценка)
+func (s *Service) loadRatingMatrix(ctx context.Context, userID int64) (map[int64]int, map[int64]map[int64]int, error) {
+	own, err := s.repo.GetRatings(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := make(map[int64]int, len(own))
+	for _, r := range own {
+		target[r.MovieID] = r.Rating
+	}
+	if len(target) == 0 {
+		return target, nil, nil
+	}
+
+	neighbours, err := s.repo.GetNeighbourRatings(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	others := make(map[int64]map[int64]int)
+	for _, r := range neighbours {
+		if others[r.UserID] == nil {
+			others[r.UserID] = make(map[int64]int)
+		}
+		others[r.UserID][r.MovieID] = r.Rating
+	}
+	return target, others, nil
+}
+
+// scoreItemBased предсказывает оценки пользователя для фильмов, которые
+// оценили другие пользователи. Сходство фильмов считается по adjusted cosine,
+// результат отсортирован по убыванию предсказанной оценки.
+func scoreItemBased(target map[int64]int, others map[int64]map[int64]int, exclude map[int64]bool) []recCandidate {
+	// центрированные оценки: фильм -> пользователь -> (оценка - средняя оценка пользователя)
+	centered := make(map[int64]map[int64]float64)
+	for userID, ratings := range others {
+		var sum float64
+		for _, r := range ratings {
+			sum += float64(r)
+		}
+		mean := sum / float64(len(ratings))
+		for movieID, r := range ratings {
+			if centered[movieID] == nil {
+				centered[movieID] = make(map[int64]float64)
+			}
+			centered[movieID][userID] = float64(r) - mean
+		}
+	}
+
+	var targetSum float64
+	for _, r := range target {
+		targetSum += float64(r)
+	}
+	targetMean := targetSum / float64(len(target))
+
+	var result []recCandidate
+	for movieID, jVec := range centered {
+		if _, rated := target[movieID]; rated || exclude[movieID] {
+			continue
+		}
+
+		var num, den, bestSim float64
+		var because int64
+		for ratedID, rating := range target {
+			sim := itemSimilarity(centered[ratedID], jVec)
+			if sim <= 0 {
+				continue
+			}
+			num += sim * (float64(rating) - targetMean)
+			den += sim
+			if rating >= likedRatingThreshold && (sim > bestSim || (sim == bestSim && ratedID < because)) {
+				bestSim = sim
+				because = ratedID
+			}
+		}
+		if den == 0 {
+			continue
+		}
+
+		score := targetMean + num/den
+		score = math.Max(1, math.Min(10, score))
+		result = append(result, recCandidate{
+			MovieID:   movieID,
+			Score:     score,
+			Support:   den,
+			BecauseID: because,
+		})
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Score != result[b].Score {
+			return result[a].Score > result[b].Score
+		}
+		if result[a].Support != result[b].Support {
+			return result[a].Support > result[b].Support
+		}
+		return result[a].MovieID < result[b].MovieID
+	})
+	return result
+}
+
+// itemSimilarity считает adjusted cosine между двумя фильмами по общим оценкам
+func itemSimilarity(a, b map[int64]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot, normA, normB float64
+	var common int
+	for userID, va := range a {
+		vb, ok := b[userID]
+		if !ok {
+			continue
+		}
+		dot += va * vb
+		normA += va * va
+		normB += vb * vb
+		common++
+	}
+	if common == 0 || normA == 0 || normB == 0 {
+		return 0
+	}
+	sim := dot / (math.Sqrt(normA) * math.Sqrt(normB))
+	return sim * float64(common) / (float64(common) + similarityShrinkage)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreItemBased(t *testing.T) {
+	// пользователь 1 любит фильм 1 и не любит фильм 2
+	target := map[int64]int{1: 9, 2: 3}
+	others := map[int64]map[int64]int{
+		// вкусы совпадают с целевым: 3 похож на 1, 4 похож на 2
+		2: {1: 10, 2: 2, 3: 9, 4: 3},
+		3: {1: 9, 2: 4, 3: 10, 4: 2},
+		4: {1: 8, 2: 3, 3: 8, 4: 4, 5: 7},
+	}
+
+	got := scoreItemBased(target, others, map[int64]bool{5: true})
+
+	assert.Len(t, got, 2)
+	assert.Equal(t, int64(3), got[0].MovieID)
+	assert.Equal(t, int64(1), got[0].BecauseID)
+	assert.Equal(t, int64(4), got[1].MovieID)
+	assert.Greater(t, got[0].Score, got[1].Score)
+	assert.Zero(t, got[1].BecauseID)
+}
+
+func TestScoreItemBased_NoOverlap(t *testing.T) {
+	target := map[int64]int{1: 8}
+	others := map[int64]map[int64]int{
+		2: {2: 9, 3: 4},
+	}
+
+	got := scoreItemBased(target, others, nil)
+
+	assert.Empty(t, got)
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/repository"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+)
+
+// --- Reviews ---
+
+// ReviewProvider — источник рецензий на фильм. Reviews возвращает до limit
+// рецензий, лучшие первыми, и должен прерываться по отмене ctx.
+type ReviewProvider interface {
+	Name() string
+	Reviews(ctx context.Context, m *models.Movie, limit int) ([]models.Review, error)
+}
+
+// DefaultReviewProviderTimeout — сколько ждать ответа одного источника рецензий
+const DefaultReviewProviderTimeout = 5 * time.Second
+
+// reviewsPerProvider — сколько рецензий брать из каждого источника
+const reviewsPerProvider = 10
+
+// rrfK сглаживает слияние выдач по обратному рангу (reciprocal rank fusion):
+// рецензия на месте rank в своём источнике получает 1/(rrfK+rank)
+const rrfK = 10
+
+// Итог запроса к источнику (ReviewSourceStatus.Status)
+const (
+	reviewSourceOK          = "ok"
+	reviewSourceTimeout     = "timeout"
+	reviewSourceUnavailable = "unavailable"
+)
+
+// ErrReviewsUnavailable — не ответил ни один источник рецензий
+var ErrReviewsUnavailable = newError(ErrUnavailable, "reviews are temporarily unavailable")
+
+type reviewSource struct {
+	provider ReviewProvider
+	timeout  time.Duration // 0 — DefaultReviewProviderTimeout
+}
+
+// defaultReviewSources — встроенные источники: рецензии пользователей
+// сервиса, зрителей Kinopoisk и видеообзоры с YouTube
+func defaultReviewSources(s *Service) []reviewSource {
+	return []reviewSource{
+		{provider: userReviews{s.repo}},
+		{provider: &kinopoiskReviews{client: s.kpClient}},
+		{provider: youtubeReviews{s}},
+	}
+}
+
+// AddReviewProvider подключает ещё один источник рецензий; timeout — сколько
+// ждать его ответа (0 — DefaultReviewProviderTimeout)
+func (s *Service) AddReviewProvider(p ReviewProvider, timeout time.Duration) {
+	s.reviewSources = append(s.reviewSources, reviewSource{provider: p, timeout: timeout})
+}
+
+// SetReviewProviderTimeout задаёт время ожидания подключённых источников
+// рецензий (REVIEW_PROVIDER_TIMEOUT)
+func (s *Service) SetReviewProviderTimeout(timeout time.Duration) {
+	for i := range s.reviewSources {
+		s.reviewSources[i].timeout = timeout
+	}
+}
+
+// GetMovieReviews собирает рецензии на фильм из всех источников параллельно.
+// Источник, не ответивший вовремя или недоступный, пропускается и отмечается
+// в Sources; ошибка — только если не ответил ни один.
+func (s *Service) GetMovieReviews(ctx context.Context, id int64) (*models.ReviewList, error) {
+	m, err := s.GetMovie(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return collectReviews(ctx, s.reviewSources, m, reviewsPerProvider)
+}
+
+type providerResult struct {
+	index   int
+	reviews []models.Review
+	err     error
+}
+
+// collectReviews опрашивает источники параллельно, каждый со своим таймаутом,
+// и сливает их выдачи по обратному рангу; при равенстве выше источник,
+// подключённый раньше. Источник, не уважающий отмену ctx, не задерживает
+// ответ дольше своего таймаута.
+func collectReviews(ctx context.Context, sources []reviewSource, m *models.Movie, limit int) (*models.ReviewList, error) {
+	results := make(chan providerResult, len(sources))
+	for i, src := range sources {
+		timeout := src.timeout
+		if timeout <= 0 {
+			timeout = DefaultReviewProviderTimeout
+		}
+		go func() {
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			done := make(chan providerResult, 1)
+			go func() {
+				reviews, err := src.provider.Reviews(pctx, m, limit)
+				done <- providerResult{i, reviews, err}
+			}()
+			select {
+			case r := <-done:
+				results <- r
+			case <-pctx.Done():
+				results <- providerResult{index: i, err: pctx.Err()}
+			}
+		}()
+	}
+
+	got := make([]providerResult, len(sources))
+	for range sources {
+		select {
+		case r := <-results:
+			got[r.index] = r
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	type ranked struct {
+		review models.Review
+		score  float64
+		source int
+	}
+	var all []ranked
+	list := &models.ReviewList{Items: []models.Review{}, Sources: []models.ReviewSourceStatus{}}
+	seen := make(map[string]bool)
+	answered := 0
+	for i, src := range sources {
+		status := models.ReviewSourceStatus{Source: src.provider.Name(), Status: reviewSourceOK}
+		switch r := got[i]; {
+		case errors.Is(r.err, context.DeadlineExceeded):
+			status.Status = reviewSourceTimeout
+		case r.err != nil:
+			status.Status = reviewSourceUnavailable
+			if !errors.Is(r.err, ErrUnavailable) {
+				log.Printf("reviews of movie %d from %s: %v", m.ID, status.Source, r.err)
+			}
+		default:
+			answered++
+			for rank, rv := range r.reviews {
+				if rv.Source == "" {
+					rv.Source = status.Source
+				}
+				if key := rv.Source + "/" + rv.ID; !seen[key] {
+					seen[key] = true
+					all = append(all, ranked{rv, 1 / float64(rrfK+rank), i})
+					status.Count++
+				}
+			}
+		}
+		list.Sources = append(list.Sources, status)
+	}
+	if answered == 0 && len(sources) > 0 {
+		return nil, ErrReviewsUnavailable
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].source < all[j].source
+	})
+	for _, r := range all {
+		list.Items = append(list.Items, r.review)
+	}
+	return list, nil
+}
+
+// sentimentFromRating определяет тональность рецензии по оценке автора
+func sentimentFromRating(rating int) string {
+	switch {
+	case rating >= 7:
+		return models.SentimentPositive
+	case rating <= 4:
+		return models.SentimentNegative
+	}
+	return models.SentimentNeutral
+}
+
+// --- Review providers ---
+
+// userReviews — рецензии пользователей сервиса к их оценкам, самые полезные первыми
+type userReviews struct {
+	repo *repository.Repo
+}
+
+func (userReviews) Name() string { return models.ReviewSourceUsers }
+
+func (p userReviews) Reviews(ctx context.Context, m *models.Movie, limit int) ([]models.Review, error) {
+	found, _, err := p.repo.ListUserReviews(ctx, m.ID, repository.ReviewsByHelpful, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+	return UserReviews(found), nil
+}
+
+// UserReviews переводит рецензии пользователей в общий вид
+func UserReviews(found []models.UserReview) []models.Review {
+	reviews := make([]models.Review, 0, len(found))
+	for _, ur := range found {
+		reviewedAt := ur.ReviewedAt
+		reviews = append(reviews, models.Review{
+			Source:      models.ReviewSourceUsers,
+			ID:          strconv.FormatInt(ur.UserID, 10),
+			Title:       ur.Title,
+			UserID:      ur.UserID,
+			Text:        ur.Text,
+			Sentiment:   sentimentFromRating(ur.Rating),
+			Rating:      ur.Rating,
+			Helpful:     ur.Helpful,
+			Spoiler:     ur.Spoiler,
+			PublishedAt: &reviewedAt,
+		})
+	}
+	return reviews
+}
+
+// kinopoiskReviewsTTL — сколько хранить в памяти рецензии зрителей Kinopoisk:
+// суточная квота ключа невелика, а новые рецензии появляются редко
+const kinopoiskReviewsTTL = 24 * time.Hour
+
+// maxCachedKinopoiskReviews — для скольких фильмов хранить рецензии в памяти
+const maxCachedKinopoiskReviews = 1000
+
+// moscow — часовой пояс дат в ответах Kinopoisk
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// kinopoiskReviews — рецензии зрителей Kinopoisk, самые полезные первыми
+type kinopoiskReviews struct {
+	client *kinopoisk.Client
+
+	mu    sync.Mutex
+	cache map[int64]cachedReviews
+}
+
+type cachedReviews struct {
+	reviews   []models.Review
+	fetchedAt time.Time
+}
+
+func (*kinopoiskReviews) Name() string { return models.ReviewSourceKinopoisk }
+
+func (p *kinopoiskReviews) Reviews(ctx context.Context, m *models.Movie, limit int) ([]models.Review, error) {
+	reviews, ok := p.cached(m.ID, time.Now())
+	if !ok {
+		page, err := p.client.GetReviews(ctx, m.ID, 1, kinopoisk.ReviewsByHelpfulness)
+		if errors.Is(err, kinopoisk.ErrNotFound) {
+			page, err = &kinopoisk.ReviewPage{}, nil // рецензий нет
+		}
+		if err != nil {
+			return nil, kinopoiskError(err, "movie not found")
+		}
+		reviews = KinopoiskReviews(page.Items)
+		p.store(m.ID, reviews, time.Now())
+	}
+	return reviews[:min(limit, len(reviews))], nil
+}
+
+func (p *kinopoiskReviews) cached(movieID int64, now time.Time) ([]models.Review, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.cache[movieID]
+	if !ok || now.Sub(c.fetchedAt) >= kinopoiskReviewsTTL {
+		return nil, false
+	}
+	return c.reviews, true
+}
+
+// store запоминает рецензии фильма; когда места нет, вытесняется самая старая запись
+func (p *kinopoiskReviews) store(movieID int64, reviews []models.Review, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cache == nil {
+		p.cache = make(map[int64]cachedReviews)
+	}
+	if _, ok := p.cache[movieID]; !ok && len(p.cache) >= maxCachedKinopoiskReviews {
+		var oldest int64
+		for id, c := range p.cache {
+			if oldest == 0 || c.fetchedAt.Before(p.cache[oldest].fetchedAt) {
+				oldest = id
+			}
+		}
+		delete(p.cache, oldest)
+	}
+	p.cache[movieID] = cachedReviews{reviews, now}
+}
+
+// KinopoiskReviews переводит рецензии зрителей Kinopoisk в общий вид
+func KinopoiskReviews(items []kinopoisk.Review) []models.Review {
+	reviews := make([]models.Review, 0, len(items))
+	for _, kr := range items {
+		r := models.Review{
+			Source:  models.ReviewSourceKinopoisk,
+			ID:      strconv.FormatInt(kr.KinopoiskID, 10),
+			Title:   kr.Title,
+			Author:  kr.Author,
+			Text:    kr.Description,
+			Helpful: kr.PositiveRating,
+		}
+		switch kr.Type {
+		case kinopoisk.ReviewPositive, kinopoisk.ReviewNegative, kinopoisk.ReviewNeutral:
+			r.Sentiment = strings.ToLower(kr.Type)
+		}
+		if t, err := time.ParseInLocation("2006-01-02T15:04:05", kr.Date, moscow); err == nil {
+			r.PublishedAt = &t
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews
+}
+
+// youtubeReviews — видеообзоры с YouTube, сохранённые в БД (см. loadVideos)
+type youtubeReviews struct {
+	s *Service
+}
+
+func (youtubeReviews) Name() string { return models.ReviewSourceYouTube }
+
+func (p youtubeReviews) Reviews(ctx context.Context, m *models.Movie, limit int) ([]models.Review, error) {
+	// первая загрузка доводится до конца и после таймаута источника, чтобы
+	// найденные обзоры достались следующему запросу; её ограничивает таймаут
+	// клиента YouTube
+	loaded := make(chan error, 1)
+	go func() { loaded <- p.s.loadVideos(context.WithoutCancel(ctx), m, reviewsGroup) }()
+	select {
+	case err := <-loaded:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	videos, err := p.s.movieVideos(ctx, m.ID, []string{models.VideoReview})
+	if err != nil {
+		return nil, err
+	}
+	return YouTubeReviews(videos[:min(limit, len(videos))]), nil
+}
+
+// YouTubeReviews переводит видеообзоры в общий вид рецензий
+func YouTubeReviews(videos []models.Video) []models.Review {
+	reviews := make([]models.Review, 0, len(videos))
+	for _, v := range videos {
+		reviews = append(reviews, models.Review{
+			Source:      models.ReviewSourceYouTube,
+			ID:          v.VideoID,
+			Title:       v.Title,
+			Author:      v.ChannelTitle,
+			PublishedAt: v.PublishedAt,
+			Video:       &v,
+		})
+	}
+	return reviews
+}
+
+// searchReviews ищет обзоры фильма на YouTube, отбирая их по названиям,
+// году и длительности фильма
+func (s *Service) searchReviews(ctx context.Context, m *models.Movie) ([]models.Video, error) {
+	found, err := s.ytClient.FindReviews(ctx, movieQuery(m, reviewsPerMovie))
+	if err != nil {
+		return nil, err
+	}
+	return youtubeVideos(found), nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider отдаёт ids как рецензии через delay (или ошибку err);
+// при ignoreCtx не замечает отмену контекста
+type fakeProvider struct {
+	name      string
+	ids       []string
+	delay     time.Duration
+	err       error
+	ignoreCtx bool
+}
+
+func (p fakeProvider) Name() string { return p.name }
+
+func (p fakeProvider) Reviews(ctx context.Context, m *models.Movie, limit int) ([]models.Review, error) {
+	if p.ignoreCtx {
+		time.Sleep(p.delay)
+	} else {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	var out []models.Review
+	for _, id := range p.ids[:min(limit, len(p.ids))] {
+		out = append(out, models.Review{ID: id})
+	}
+	return out, nil
+}
+
+func reviewIDs(list *models.ReviewList) []string {
+	var ids []string
+	for _, r := range list.Items {
+		ids = append(ids, r.Source+":"+r.ID)
+	}
+	return ids
+}
+
+func TestCollectReviews(t *testing.T) {
+	m := &models.Movie{ID: 301}
+	sources := []reviewSource{
+		{provider: fakeProvider{name: "a", ids: []string{"1", "2", "3"}}},
+		{provider: fakeProvider{name: "b", ids: []string{"1", "2"}, delay: 10 * time.Millisecond}},
+		{provider: fakeProvider{name: "slow", ids: []string{"1"}, delay: time.Second}, timeout: 50 * time.Millisecond},
+		{provider: fakeProvider{name: "stuck", ids: []string{"1"}, delay: time.Second, ignoreCtx: true}, timeout: 50 * time.Millisecond},
+		{provider: fakeProvider{name: "broken", err: errors.New("boom")}},
+		{provider: fakeProvider{name: "quota", err: ErrReviewsUnavailable}},
+	}
+
+	start := time.Now()
+	list, err := collectReviews(context.Background(), sources, m, 10)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "slow providers must not delay the answer")
+
+	// выдачи чередуются по рангу, при равенстве первым идёт источник, подключённый раньше
+	assert.Equal(t, []string{"a:1", "b:1", "a:2", "b:2", "a:3"}, reviewIDs(list))
+	assert.Equal(t, []models.ReviewSourceStatus{
+		{Source: "a", Status: "ok", Count: 3},
+		{Source: "b", Status: "ok", Count: 2},
+		{Source: "slow", Status: "timeout"},
+		{Source: "stuck", Status: "timeout"},
+		{Source: "broken", Status: "unavailable"},
+		{Source: "quota", Status: "unavailable"},
+	}, list.Sources)
+}
+
+func TestCollectReviews_AllFailed(t *testing.T) {
+	m := &models.Movie{ID: 301}
+	sources := []reviewSource{
+		{provider: fakeProvider{name: "slow", delay: time.Second}, timeout: 10 * time.Millisecond},
+		{provider: fakeProvider{name: "broken", err: errors.New("boom")}},
+	}
+	_, err := collectReviews(context.Background(), sources, m, 10)
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	// без источников — пустой список, а не ошибка
+	list, err := collectReviews(context.Background(), nil, m, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestCollectReviews_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sources := []reviewSource{{provider: fakeProvider{name: "a", ids: []string{"1"}, delay: time.Second}}}
+	_, err := collectReviews(ctx, sources, &models.Movie{ID: 301}, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestKinopoiskReviews(t *testing.T) {
+	reviews := KinopoiskReviews([]kinopoisk.Review{
+		{KinopoiskID: 11, Type: kinopoisk.ReviewPositive, Date: "2010-08-24T19:08:00", PositiveRating: 120, Author: "Зритель", Title: "Шедевр", Description: "Текст"},
+		{KinopoiskID: 12, Type: "UNKNOWN", Date: "bad"},
+	})
+	require.Len(t, reviews, 2)
+	assert.Equal(t, models.Review{
+		Source:      models.ReviewSourceKinopoisk,
+		ID:          "11",
+		Title:       "Шедевр",
+		Author:      "Зритель",
+		Text:        "Текст",
+		Sentiment:   models.SentimentPositive,
+		Helpful:     120,
+		PublishedAt: reviews[0].PublishedAt,
+	}, reviews[0])
+	assert.True(t, reviews[0].PublishedAt.Equal(time.Date(2010, 8, 24, 16, 8, 0, 0, time.UTC)), "dates are in Moscow time")
+	assert.Empty(t, reviews[1].Sentiment)
+	assert.Nil(t, reviews[1].PublishedAt)
+}
+
+func TestKinopoiskReviews_Cache(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var p kinopoiskReviews
+	p.store(301, []models.Review{{ID: "1"}}, now)
+
+	_, ok := p.cached(301, now.Add(kinopoiskReviewsTTL-time.Minute))
+	assert.True(t, ok)
+	_, ok = p.cached(301, now.Add(kinopoiskReviewsTTL))
+	assert.False(t, ok, "expired")
+
+	for i := 1; i < maxCachedKinopoiskReviews; i++ {
+		p.store(int64(1000+i), nil, now.Add(time.Duration(i)*time.Second))
+	}
+	p.store(302, nil, now.Add(time.Hour))
+	assert.Len(t, p.cache, maxCachedKinopoiskReviews)
+	_, ok = p.cached(301, now)
+	assert.False(t, ok, "the oldest entry is evicted")
+}
+
+func TestUserReviews(t *testing.T) {
+	at := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var found []models.UserReview
+	for i, rating := range []int{9, 5, 3} {
+		found = append(found, models.UserReview{UserID: int64(i + 1), MovieID: 301, Rating: rating, Text: fmt.Sprint("текст ", i), ReviewedAt: at})
+	}
+	found[0].Helpful, found[0].Spoiler = 4, true
+	reviews := UserReviews(found)
+	require.Len(t, reviews, 3)
+	assert.Equal(t, "1", reviews[0].ID)
+	assert.Equal(t, int64(1), reviews[0].UserID)
+	assert.Equal(t, 9, reviews[0].Rating)
+	assert.Equal(t, 4, reviews[0].Helpful)
+	assert.True(t, reviews[0].Spoiler)
+	assert.Equal(t, at, *reviews[0].PublishedAt)
+	assert.Equal(t, []string{models.SentimentPositive, models.SentimentNeutral, models.SentimentNegative},
+		[]string{reviews[0].Sentiment, reviews[1].Sentiment, reviews[2].Sentiment})
+}
@@ -1,73 +1,121 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"time"
 	"log"
+	"strings"
 
 	"github.com/AlexKeyyyy/movies-picker/internal/models"
 	"github.com/AlexKeyyyy/movies-picker/internal/repository"
 	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/mailer"
 	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Service struct {
-	repo      *repository.Repo
-	kpClient  *kinopoisk.Client
-	ytClient  *youtube.Client
-	jwtSecret string
+	repo          *repository.Repo
+	kpClient      *kinopoisk.Client
+	ytClient      *youtube.Client
+	mailer        mailer.Mailer
+	fetches       fetchGroup[int64, *models.Movie] // загрузки недостающих фильмов из Kinopoisk
+	missing       missingMovies                    // ID, которых нет в Kinopoisk
+	syncer        *catalogSync                     // фоновое обновление каталога; nil, пока не запущено
+	videos        videoCache                       // сроки свежести и фоновое обновление видео фильмов
+	reviewSources []reviewSource                   // источники рецензий в порядке приоритета
+	jwtSecret     string
+	appURL        string // адрес фронтенда для ссылок в письмах
 }
 
-func NewService(repo *repository.Repo, kp *kinopoisk.Client, yt *youtube.Client, jwtSecret string) *Service {
-	return &Service{repo: repo, kpClient: kp, ytClient: yt, jwtSecret: jwtSecret}
+func NewService(repo *repository.Repo, kp *kinopoisk.Client, yt *youtube.Client, ml mailer.Mailer, jwtSecret, appURL string) *Service {
+	s := &Service{repo: repo, kpClient: kp, ytClient: yt, mailer: ml, jwtSecret: jwtSecret, appURL: appURL}
+	s.reviewSources = defaultReviewSources(s)
+	return s
 }
 
 // --- Auth ---
-func (s *Service) Register(email, password string) (*models.User, error) {
+func (s *Service) Register(ctx context.Context, email, password string) (*models.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 	user := &models.User{Email: email, PasswordHash: string(hashed)}
-	if err := s.repo.CreateUser(user); err != nil {
+	if err := s.repo.CreateUser(ctx, user); errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrEmailTaken
+	} else if err != nil {
 		return nil, err
 	}
+	// письмо не критично: подтверждение можно запросить повторно сменой email
+	if err := s.sendVerification(ctx, user.ID, email); err != nil {
+		log.Printf("send verification to user %d: %v", user.ID, err)
+	}
 	return user, nil
 }
 
-func (s *Service) Login(email, password string) (string, error) {
-	user, err := s.repo.GetUserByEmail(email)
+// ErrInvalidCredentials — неизвестный email или неверный пароль (не различаются)
+var ErrInvalidCredentials = newError(ErrUnauthorized, "invalid credentials")
+
+// Login проверяет пароль, открывает новую сессию входа и выдаёт пару токенов
+func (s *Service) Login(ctx context.Context, email, password string) (*models.TokenPair, error) {
+	user, err := s.repo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour).Unix(),
+	if user.DisabledAt != nil {
+		return nil, ErrUserDisabled
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	sessionID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAuthSession(ctx, sessionID, user.ID, hashToken(refresh), refreshTokenTTL); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user.ID, user.Role, sessionID, refresh)
 }
 
 // GetProfile возвращает профиль текущего пользователя
-func (s *Service) GetProfile(userID int64) (*models.User, error) {
-	return s.repo.GetUserByID(userID)
+func (s *Service) GetProfile(ctx context.Context, userID int64) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	return user, notFound(err, "user not found")
 }
 
-// UpdateProfile обновляет профиль пользователя (email и/или пароль)
-func (s *Service) UpdateProfile(userID int64, newEmail, newPassword string) (*models.User, error) {
-	user, err := s.repo.GetUserByID(userID)
+// UpdateProfile обновляет профиль пользователя. Новый email вступает в силу
+// только после подтверждения по ссылке из письма. Смена пароля завершает все
+// сессии, кроме sessionID, из которой он сменён.
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, sessionID, newEmail, newPassword string) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "user not found")
 	}
 
 	if newEmail != "" && newEmail != user.Email {
-		user.Email = newEmail
+		if newEmail, err = normalizeEmail(newEmail); err != nil {
+			return nil, err
+		}
+		if err := s.ensureEmailFree(ctx, newEmail, userID); err != nil {
+			return nil, err
+		}
+		if err := s.sendVerification(ctx, userID, newEmail); err != nil {
+			return nil, err
+		}
 	}
 
 	if newPassword != "" {
@@ -75,143 +123,230 @@ func (s *Service) UpdateProfile(userID int64, newEmail, newPassword string) (*mo
 		if err != nil {
 			return nil, err
 		}
-		user.PasswordHash = string(hashed)
+		if err := s.repo.ChangePassword(ctx, userID, string(hashed), sessionID); err != nil {
+			return nil, err
+		}
 	}
+	return s.repo.GetUserByID(ctx, userID)
+}
 
-	if err := s.repo.UpdateUser(user); err != nil {
-		return nil, err
+// --- Movies ---
+
+// Источники результатов поиска
+const (
+	SourceLocal  = "local"
+	SourceRemote = "remote"
+)
+
+// normalizePage приводит параметры пагинации к допустимым значениям
+func normalizePage(page, size int) (int, int) {
+	if page < 1 {
+		page = 1
 	}
-	return user, nil
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	return page, size
 }
 
-// --- Movies ---
-func (s *Service) SearchMovies(query string) ([]models.Movie, error) {
+// newMovieList собирает страницу списка фильмов
+func newMovieList(items []models.Movie, page, size, total int, source string) *models.MovieList {
+	if items == nil {
+		items = []models.Movie{}
+	}
+	return &models.MovieList{
+		Items:      items,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+		Source:     source,
+	}
+}
+
+// SearchMovies ищет фильмы постранично. source: "" — сначала в БД, затем
+// через Kinopoisk API, если локально ничего нет; local/remote — только указанный
+// источник (клиент передаёт source из первой страницы, чтобы листать дальше).
+func (s *Service) SearchMovies(ctx context.Context, query string, page, size int, source string) (*models.MovieList, error) {
+	page, size = normalizePage(page, size)
+	switch source {
+	case "", SourceLocal, SourceRemote:
+	default:
+		return nil, invalid("source", "must be local or remote")
+	}
+
 	// 1) Сначала пытаемся найти в БД
-	movies, err := s.repo.SearchMovies(query)
-	if len(movies) > 0 {
-		return movies, nil
+	if source != SourceRemote {
+		movies, total, err := s.repo.SearchMovies(ctx, query, (page-1)*size, size)
+		if err != nil {
+			return nil, err
+		}
+		if total > 0 || source == SourceLocal {
+			return newMovieList(movies, page, size, total, SourceLocal), nil
+		}
 	}
 
-	// 2) Иначе — ищем по API
-	films, totalPages, err := s.kpClient.SearchByKeyword(query, 1)
+	// 2) Иначе — ищем по API, запрашивая только нужные страницы
+	return s.searchRemote(ctx, query, page, size)
+}
+
+// searchRemote загружает из Kinopoisk только страницы, покрывающие
+// запрошенный диапазон, и сохраняет найденные фильмы в БД
+func (s *Service) searchRemote(ctx context.Context, query string, page, size int) (*models.MovieList, error) {
+	films, total, err := searchWindow(page, size, func(p int) (*kinopoisk.CollectionsResponse, error) {
+		return s.kpClient.SearchByKeywordPage(ctx, query, p)
+	})
 	if err != nil {
-		return nil, err
+		return nil, kinopoiskError(err, "no movies found")
 	}
 
-	var result []models.Movie
+	// клиент ушёл — найденное не сохраняем
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := make([]models.Movie, 0, len(films))
 	for _, f := range films {
-		m := s.mapFilmToModel(f)
-		_ = s.repo.UpsertMovie(&m)
+		m := MovieFromFilm(f)
+		if err := s.repo.UpsertMovie(ctx, &m); errors.Is(err, repository.ErrMovieRemoved) {
+			continue // удалён модератором
+		}
 		result = append(result, m)
 	}
+	return newMovieList(result, page, size, total, SourceRemote), nil
+}
+
+// searchWindow вырезает страницу page размера size из выдачи Kinopoisk,
+// запрашивая через fetch только страницы API (по kinopoisk.PageSize фильмов),
+// которые её покрывают. total — сколько результатов можно получить из API.
+func searchWindow(page, size int, fetch func(p int) (*kinopoisk.CollectionsResponse, error)) ([]kinopoisk.Film, int, error) {
+	first := (page - 1) * size
+	firstPage := first/kinopoisk.PageSize + 1
+	lastPage := (first+size-1)/kinopoisk.PageSize + 1
 
-	for page := 2; page <= totalPages; page++ {
-		films, _, err := s.kpClient.SearchByKeyword(query, page)
+	var films []kinopoisk.Film
+	total := 0
+	for p := firstPage; p <= lastPage; p++ {
+		resp, err := fetch(p)
 		if err != nil {
-			continue
+			return nil, 0, err
+		}
+		// API отдаёт не больше totalPages страниц, даже если total больше
+		total = resp.Total
+		if reachable := resp.TotalPages * kinopoisk.PageSize; total > reachable {
+			total = reachable
 		}
-		for _, f := range films {
-			m := s.mapFilmToModel(f)
-			_ = s.repo.UpsertMovie(&m)
-			result = append(result, m)
+		films = append(films, resp.Items...)
+		if p >= resp.TotalPages {
+			break
 		}
 	}
-	return result, nil
+
+	skip := first - (firstPage-1)*kinopoisk.PageSize
+	if skip > len(films) {
+		skip = len(films)
+	}
+	films = films[skip:]
+	if len(films) > size {
+		films = films[:size]
+	}
+	return films, total, nil
 }
 
-func (s *Service) mapFilmToModel(f kinopoisk.Film) models.Movie {
+// MovieFromFilm переводит карточку Kinopoisk в модель каталога
+func MovieFromFilm(f kinopoisk.Film) models.Movie {
 	yearInt, _ := f.Year.Int64()
 	return models.Movie{
-		ID:          f.KinopoiskID,
-		Title:       f.NameRu,
-		Year:        int(yearInt),
-		Description: f.Description,
-		PosterURL:   f.PosterURL,
+		ID:              f.KinopoiskID,
+		Title:           f.NameRu,
+		Year:            int(yearInt),
+		Description:     f.Description,
+		PosterURL:       f.PosterURL,
+		RatingKinopoisk: f.RatingKinopoisk,
+		TitleEn:         f.NameEn,
+		OriginalTitle:   f.NameOriginal,
+		Runtime:         f.FilmLength,
+		AgeRating:       f.RatingAgeLimits,
+		RatingImdb:      f.RatingImdb,
+		Type:            f.Type,
+		Genres:          f.GenreNames(),
+		Countries:       f.CountryNames(),
 	}
 }
 
-func (s *Service) GetMovie(id int64) (*models.Movie, error) {
-	return s.repo.GetMovieByID(id)
-}
-
-// ListMovies отдаёт фильмы по страницам
-func (s *Service) ListMovies(page, size int) ([]models.Movie, error) {
-	if page < 1 {
-		page = 1
+// ListMovies отдаёт отфильтрованные фильмы по страницам вместе с фасетами
+func (s *Service) ListMovies(ctx context.Context, f models.MovieFilter, page, size int) (*models.MovieList, error) {
+	if f.Sort != "" && !repository.IsValidMovieSort(f.Sort) {
+		return nil, invalid("sort", fmt.Sprintf("unknown sort field %q", f.Sort))
 	}
-	if size < 1 || size > 100 {
-		size = 20
+	if f.Order != "" && f.Order != "asc" && f.Order != "desc" {
+		return nil, invalid("order", "must be asc or desc")
 	}
+	page, size = normalizePage(page, size)
 	offset := (page - 1) * size
-	return s.repo.ListMovies(offset, size)
-}
-
-// ListPopular возвращает топ-N популярных фильмов
-func (s *Service) ListPopular(limit int) ([]models.Movie, error) {
-	if limit < 1 {
-		limit = 10
+	movies, err := s.repo.ListMovies(ctx, f, offset, size)
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.ListPopularMovies(limit)
-}
-
-// --- Reviews ---
-
-// GetMovieReviews возвращает список обзоров для фильма по его ID
-func (s *Service) GetMovieReviews(id int64) ([]models.ReviewItem, error) {
-	m, err := s.repo.GetMovieByID(id)
+	total, err := s.repo.CountMovies(ctx, f)
 	if err != nil {
-		log.Printf("GetMovieByID failed for id %d: %v", id, err)
-		return nil, fmt.Errorf("movie not found: %w", err)
+		return nil, err
 	}
-	
-	reviews, err := s.ytClient.SearchReviews(m.Title, 10)
+	facets, err := s.repo.GetMovieFacets(ctx, f)
 	if err != nil {
-		log.Printf("YouTube search failed for movie title '%s': %v", m.Title, err)
-		return nil, fmt.Errorf("youtube search failed: %w", err)
+		return nil, err
 	}
-	
+	list := newMovieList(movies, page, size, total, SourceLocal)
+	list.Facets = facets
+	return list, nil
+}
 
-	var out []models.ReviewItem
-	for _, r := range reviews {
-		out = append(out, models.ReviewItem{
-			VideoID:      r.VideoID,
-			VideoURL:     r.VideoURL,
-			Title:        r.Title,
-			ChannelTitle: r.ChannelTitle,
-			ThumbnailURL: r.ThumbnailURL,
-		})
+// ListPopular возвращает популярные фильмы по страницам
+func (s *Service) ListPopular(ctx context.Context, page, size int) (*models.MovieList, error) {
+	page, size = normalizePage(page, size)
+	movies, err := s.repo.ListPopularMovies(ctx, (page-1)*size, size)
+	if err != nil {
+		return nil, err
 	}
-
-	return out, nil
+	total, err := s.repo.CountMovies(ctx, models.MovieFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return newMovieList(movies, page, size, total, SourceLocal), nil
 }
 
 // --- Watchlist ---
-func (s *Service) AddToWatchlist(userID, movieID int64) error {
+func (s *Service) AddToWatchlist(ctx context.Context, userID, movieID int64) error {
+	if err := s.ensureMovie(ctx, movieID); err != nil {
+		return err
+	}
 	item := &models.WatchlistItem{
 		UserID:  userID,
 		MovieID: movieID,
 	}
-	return s.repo.AddToWatchlist(item)
+	return s.repo.AddToWatchlist(ctx, item)
 }
 
-func (s *Service) GetWatchlist(userID int64) ([]models.WatchlistItem, error) {
-	return s.repo.GetWatchlist(userID)
+func (s *Service) GetWatchlist(ctx context.Context, userID int64) ([]models.WatchlistItem, error) {
+	return s.repo.GetWatchlist(ctx, userID)
 }
 
-func (s *Service) RemoveFromWatchlist(userID, movieID int64) error {
-	return s.repo.RemoveFromWatchlist(userID, movieID)
+func (s *Service) RemoveFromWatchlist(ctx context.Context, userID, movieID int64) error {
+	return s.repo.RemoveFromWatchlist(ctx, userID, movieID)
 }
 
 // --- Ratings ---
-func (s *Service) UpsertRating(item *models.RatingItem) error {
-	return s.repo.UpsertRating(item)
+func (s *Service) UpsertRating(ctx context.Context, item *models.RatingItem) error {
+	if err := s.ensureMovie(ctx, item.MovieID); err != nil {
+		return err
+	}
+	return s.repo.UpsertRating(ctx, item)
 }
 
-func (s *Service) GetRatings(userID int64) ([]models.RatingItem, error) {
-	return s.repo.GetRatings(userID)
+func (s *Service) GetRatings(ctx context.Context, userID int64) ([]models.RatingItem, error) {
+	return s.repo.GetRatings(ctx, userID)
 }
 
 // DeleteRating удаляет оценку
-func (s *Service) DeleteRating(userID, movieID int64) error {
-	return s.repo.DeleteRating(userID, movieID)
+func (s *Service) DeleteRating(ctx context.Context, userID, movieID int64) error {
+	return s.repo.DeleteRating(ctx, userID, movieID)
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSearch отдаёт выдачу из total фильмов с ID 1..total страницами по
+// kinopoisk.PageSize, но не больше maxPages страниц
+type fakeSearch struct {
+	total    int
+	maxPages int
+	fetched  []int
+}
+
+func (f *fakeSearch) fetch(p int) (*kinopoisk.CollectionsResponse, error) {
+	f.fetched = append(f.fetched, p)
+	pages := (f.total + kinopoisk.PageSize - 1) / kinopoisk.PageSize
+	if pages > f.maxPages {
+		pages = f.maxPages
+	}
+	resp := &kinopoisk.CollectionsResponse{Total: f.total, TotalPages: pages}
+	if p > pages {
+		return resp, nil
+	}
+	for id := (p-1)*kinopoisk.PageSize + 1; id <= p*kinopoisk.PageSize && id <= f.total; id++ {
+		resp.Items = append(resp.Items, kinopoisk.Film{KinopoiskID: int64(id)})
+	}
+	return resp, nil
+}
+
+func filmRange(from, to int64) []int64 {
+	var ids []int64
+	for id := from; id <= to; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestSearchWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		maxPages  int
+		page      int
+		size      int
+		wantPages []int
+		wantIDs   []int64
+		wantTotal int
+	}{
+		{
+			name: "one api page", total: 100, maxPages: 20, page: 1, size: 20,
+			wantPages: []int{1}, wantIDs: filmRange(1, 20), wantTotal: 100,
+		},
+		{
+			name: "page spans two api pages", total: 100, maxPages: 20, page: 2, size: 15,
+			wantPages: []int{1, 2}, wantIDs: filmRange(16, 30), wantTotal: 100,
+		},
+		{
+			name: "size larger than api page", total: 100, maxPages: 20, page: 2, size: 50,
+			wantPages: []int{3, 4, 5}, wantIDs: filmRange(51, 100), wantTotal: 100,
+		},
+		{
+			name: "last page is short", total: 30, maxPages: 20, page: 2, size: 20,
+			wantPages: []int{2}, wantIDs: filmRange(21, 30), wantTotal: 30,
+		},
+		{
+			name: "page past total pages", total: 30, maxPages: 20, page: 3, size: 20,
+			wantPages: []int{3}, wantIDs: nil, wantTotal: 30,
+		},
+		{
+			name: "skip beyond a short api page", total: 25, maxPages: 20, page: 4, size: 10,
+			wantPages: []int{2}, wantIDs: nil, wantTotal: 25,
+		},
+		{
+			name: "stops at the last api page", total: 45, maxPages: 20, page: 1, size: 100,
+			wantPages: []int{1, 2, 3}, wantIDs: filmRange(1, 45), wantTotal: 45,
+		},
+		{
+			name: "total capped by reachable pages", total: 1000, maxPages: 5, page: 1, size: 10,
+			wantPages: []int{1}, wantIDs: filmRange(1, 10), wantTotal: 5 * kinopoisk.PageSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeSearch{total: tt.total, maxPages: tt.maxPages}
+			films, total, err := searchWindow(tt.page, tt.size, api.fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPages, api.fetched)
+			assert.Equal(t, tt.wantTotal, total)
+
+			var ids []int64
+			for _, f := range films {
+				ids = append(ids, f.KinopoiskID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestSearchWindow_Error(t *testing.T) {
+	_, _, err := searchWindow(1, 20, func(int) (*kinopoisk.CollectionsResponse, error) {
+		return nil, kinopoisk.ErrQuotaExceeded
+	})
+	assert.ErrorIs(t, err, kinopoisk.ErrQuotaExceeded)
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+)
+
+// --- Sessions ---
+
+const (
+	// вклад одного голоса «за»/«против» в итоговый score кандидата
+	sessionVoteWeight = 0.5
+	// доля минимальной предсказанной оценки в score (защита от «несчастного» участника)
+	sessionLeastMiseryWeight = 0.3
+)
+
+var (
+	ErrNotSessionMember = newError(ErrForbidden, "not a session member")
+	ErrUnknownUsers     = invalid("emails", "unknown users")
+	ErrInvalidVote      = invalid("kind", "must be one of: up, down, veto")
+)
+
+// CreateSession создаёт сессию совместного выбора и приглашает пользователей по email
+func (s *Service) CreateSession(ctx context.Context, ownerID int64, title string, emails []string) (*models.Session, error) {
+	seen := make(map[string]bool, len(emails))
+	var uniq []string
+	for _, e := range emails {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		seen[e] = true
+		uniq = append(uniq, e)
+	}
+
+	users, err := s.repo.GetUsersByEmails(ctx, uniq)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(users))
+	var invitees []int64
+	for _, u := range users {
+		found[u.Email] = true
+		if u.ID != ownerID {
+			invitees = append(invitees, u.ID)
+		}
+	}
+	var missing []string
+	for _, e := range uniq {
+		if !found[e] {
+			missing = append(missing, e)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownUsers, strings.Join(missing, ", "))
+	}
+
+	session := &models.Session{OwnerID: ownerID, Title: title}
+	if err := s.repo.CreateSession(ctx, session, invitees); err != nil {
+		return nil, err
+	}
+	if session.Members, err = s.repo.GetSessionMembers(ctx, session.ID); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetSession возвращает сессию с участниками; доступно только приглашённым
+func (s *Service) GetSession(ctx context.Context, userID, sessionID int64) (*models.Session, error) {
+	session, _, err := s.sessionForMember(ctx, userID, sessionID)
+	return session, err
+}
+
+// JoinSession принимает приглашение в сессию
+func (s *Service) JoinSession(ctx context.Context, userID, sessionID int64) (*models.Session, error) {
+	if _, _, err := s.sessionForMember(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.JoinSession(ctx, sessionID, userID); err != nil {
+		return nil, err
+	}
+	return s.GetSession(ctx, userID, sessionID)
+}
+
+// VoteInSession сохраняет голос участника за фильм: up, down или veto
+func (s *Service) VoteInSession(ctx context.Context, userID, sessionID, movieID int64, kind string) error {
+	if kind != "up" && kind != "down" && kind != "veto" {
+		return ErrInvalidVote
+	}
+	_, member, err := s.sessionForMember(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if member.Status != "joined" {
+		return ErrNotSessionMember
+	}
+	if err := s.ensureMovie(ctx, movieID); err != nil {
+		return err
+	}
+	return s.repo.UpsertSessionVote(ctx, &models.SessionVote{
+		SessionID: sessionID,
+		UserID:    userID,
+		MovieID:   movieID,
+		Kind:      kind,
+	})
+}
+
+// GetSessionResult ранжирует фильмы для совместного просмотра. Кандидаты —
+// фильмы из «Смотреть позже» присоединившихся участников и фильмы, за которые
+// голосовали; фильмы с вето исключаются. Выше стоят фильмы, которые есть в
+// списках у большего числа участников (пересечение списков — первым), затем —
+// по агрегированной предсказанной оценке с учётом голосов.
+func (s *Service) GetSessionResult(ctx context.Context, userID, sessionID int64, limit int) (*models.SessionResult, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	session, _, err := s.sessionForMember(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var joined []int64
+	for _, m := range session.Members {
+		if m.Status == "joined" {
+			joined = append(joined, m.UserID)
+		}
+	}
+
+	watchlists, err := s.repo.GetWatchlistsForUsers(ctx, joined)
+	if err != nil {
+		return nil, err
+	}
+	votes, err := s.repo.GetSessionVotes(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	tally := tallySession(watchlists, votes)
+	result := &models.SessionResult{
+		SessionID:  sessionID,
+		Members:    len(joined),
+		Candidates: []models.SessionCandidate{},
+		Vetoed:     tally.vetoedIDs(),
+	}
+	ids := tally.candidateIDs()
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	movies, err := s.repo.GetMoviesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// предсказанные оценки каждого участника для кандидатов
+	predictions := make([]map[int64]float64, 0, len(joined))
+	for _, uid := range joined {
+		target, others, err := s.loadRatingMatrix(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		p := make(map[int64]float64, len(target))
+		for movieID, r := range target {
+			p[movieID] = float64(r)
+		}
+		if len(target) > 0 {
+			for _, c := range scoreItemBased(target, others, nil) {
+				p[c.MovieID] = c.Score
+			}
+		}
+		predictions = append(predictions, p)
+	}
+
+	result.Candidates = scoreSession(movies, tally, predictions)
+	if len(result.Candidates) > limit {
+		result.Candidates = result.Candidates[:limit]
+	}
+	return result, nil
+}
+
+// sessionTally — списки и голоса участников сессии по фильмам
+type sessionTally struct {
+	inWatchlists map[int64]int
+	up           map[int64]int
+	down         map[int64]int
+	vetoed       map[int64]bool
+}
+
+// tallySession считает, в скольких списках участников есть фильм и как за него голосовали
+func tallySession(watchlists []models.WatchlistItem, votes []models.SessionVote) sessionTally {
+	t := sessionTally{
+		inWatchlists: make(map[int64]int),
+		up:           make(map[int64]int),
+		down:         make(map[int64]int),
+		vetoed:       make(map[int64]bool),
+	}
+	for _, w := range watchlists {
+		t.inWatchlists[w.MovieID]++
+	}
+	for _, v := range votes {
+		switch v.Kind {
+		case "veto":
+			t.vetoed[v.MovieID] = true
+		case "up":
+			t.up[v.MovieID]++
+		case "down":
+			t.down[v.MovieID]++
+		}
+	}
+	return t
+}
+
+// candidateIDs возвращает фильмы из списков и с голосами «за», кроме фильмов с вето
+func (t sessionTally) candidateIDs() []int64 {
+	var ids []int64
+	for id := range t.inWatchlists {
+		if !t.vetoed[id] {
+			ids = append(ids, id)
+		}
+	}
+	for id := range t.up {
+		if _, ok := t.inWatchlists[id]; !ok && !t.vetoed[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
+// vetoedIDs возвращает отсортированные фильмы с вето
+func (t sessionTally) vetoedIDs() []int64 {
+	ids := []int64{}
+	for id := range t.vetoed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
+// scoreSession ранжирует кандидатов сессии. predictions — предсказанные
+// оценки каждого участника; фильм без предсказания участник оценивает по
+// рейтингу Кинопоиска. Score смешивает среднюю и минимальную предсказанную
+// оценку (least misery) и голоса; выше стоят фильмы из большего числа списков.
+func scoreSession(movies []models.Movie, t sessionTally, predictions []map[int64]float64) []models.SessionCandidate {
+	candidates := make([]models.SessionCandidate, 0, len(movies))
+	for _, m := range movies {
+		if t.vetoed[m.ID] {
+			continue
+		}
+		c := models.SessionCandidate{
+			Movie:        m,
+			InWatchlists: t.inWatchlists[m.ID],
+			Upvotes:      t.up[m.ID],
+			Downvotes:    t.down[m.ID],
+		}
+		var sum float64
+		c.MinPredicted = math.Inf(1)
+		for _, p := range predictions {
+			pred, ok := p[m.ID]
+			if !ok {
+				// нет данных для предсказания — опираемся на рейтинг Кинопоиска
+				pred = m.RatingKinopoisk
+			}
+			sum += pred
+			c.MinPredicted = math.Min(c.MinPredicted, pred)
+		}
+		if len(predictions) == 0 {
+			c.MinPredicted = 0
+		} else {
+			c.PredictedRating = sum / float64(len(predictions))
+		}
+		c.Score = (1-sessionLeastMiseryWeight)*c.PredictedRating +
+			sessionLeastMiseryWeight*c.MinPredicted +
+			sessionVoteWeight*float64(c.Upvotes-c.Downvotes)
+
+		c.PredictedRating = math.Round(c.PredictedRating*100) / 100
+		c.MinPredicted = math.Round(c.MinPredicted*100) / 100
+		c.Score = math.Round(c.Score*100) / 100
+		candidates = append(candidates, c)
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		ca, cb := candidates[a], candidates[b]
+		if ca.InWatchlists != cb.InWatchlists {
+			return ca.InWatchlists > cb.InWatchlists
+		}
+		if ca.Score != cb.Score {
+			return ca.Score > cb.Score
+		}
+		return ca.ID < cb.ID
+	})
+	return candidates
+}
+
+// sessionForMember загружает сессию с участниками и проверяет, что userID в ней состоит
+func (s *Service) sessionForMember(ctx context.Context, userID, sessionID int64) (*models.Session, *models.SessionMember, error) {
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, notFound(err, "session not found")
+	}
+	if session.Members, err = s.repo.GetSessionMembers(ctx, sessionID); err != nil {
+		return nil, nil, err
+	}
+	for i := range session.Members {
+		if session.Members[i].UserID == userID {
+			return session, &session.Members[i], nil
+		}
+	}
+	return nil, nil, ErrNotSessionMember
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func sessionMovieIDs(cs []models.SessionCandidate) []int64 {
+	ids := make([]int64, len(cs))
+	for i, c := range cs {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestTallySession(t *testing.T) {
+	watchlists := []models.WatchlistItem{{MovieID: 1}, {MovieID: 1}, {MovieID: 2}, {MovieID: 3}}
+	votes := []models.SessionVote{
+		{UserID: 1, MovieID: 4, Kind: "up"},
+		{UserID: 2, MovieID: 4, Kind: "down"},
+		{UserID: 1, MovieID: 3, Kind: "veto"},
+		{UserID: 2, MovieID: 5, Kind: "veto"},
+		{UserID: 2, MovieID: 6, Kind: "down"},
+	}
+	tally := tallySession(watchlists, votes)
+
+	assert.Equal(t, 2, tally.inWatchlists[1])
+	// только «против» кандидатом не делает, вето исключает даже фильм из списка
+	assert.Equal(t, []int64{1, 2, 4}, tally.candidateIDs())
+	assert.Equal(t, []int64{3, 5}, tally.vetoedIDs())
+	assert.Equal(t, []int64{}, tallySession(nil, nil).vetoedIDs())
+}
+
+func TestScoreSession(t *testing.T) {
+	movies := []models.Movie{
+		{ID: 1, RatingKinopoisk: 6},
+		{ID: 2, RatingKinopoisk: 6},
+		{ID: 3, RatingKinopoisk: 9},
+	}
+	tests := []struct {
+		name        string
+		watchlists  []models.WatchlistItem
+		votes       []models.SessionVote
+		predictions []map[int64]float64
+		want        []int64
+	}{
+		{
+			name:       "intersection of watchlists first",
+			watchlists: []models.WatchlistItem{{MovieID: 1}, {MovieID: 1}, {MovieID: 2}, {MovieID: 3}},
+			predictions: []map[int64]float64{
+				{1: 5, 2: 8, 3: 10},
+				{1: 5, 2: 8, 3: 10},
+			},
+			want: []int64{1, 3, 2},
+		},
+		{
+			name: "least misery breaks equal averages",
+			predictions: []map[int64]float64{
+				{1: 7, 2: 10, 3: 1},
+				{1: 7, 2: 4, 3: 1},
+			},
+			want: []int64{1, 2, 3},
+		},
+		{
+			name: "votes lift a candidate",
+			votes: []models.SessionVote{
+				{UserID: 1, MovieID: 2, Kind: "up"},
+				{UserID: 2, MovieID: 2, Kind: "up"},
+				{UserID: 1, MovieID: 3, Kind: "down"},
+			},
+			predictions: []map[int64]float64{{1: 7, 2: 7, 3: 7}},
+			want:        []int64{2, 1, 3},
+		},
+		{
+			name:        "vetoed movies are dropped",
+			votes:       []models.SessionVote{{UserID: 1, MovieID: 3, Kind: "veto"}},
+			predictions: []map[int64]float64{{}},
+			want:        []int64{1, 2},
+		},
+		{
+			name:        "ties are ordered by id",
+			predictions: []map[int64]float64{{1: 8, 2: 8, 3: 8}},
+			want:        []int64{1, 2, 3},
+		},
+		{
+			name:        "members without ratings fall back to kinopoisk",
+			predictions: []map[int64]float64{{}, {}},
+			want:        []int64{3, 1, 2},
+		},
+		{
+			name:  "no joined members",
+			votes: []models.SessionVote{{UserID: 1, MovieID: 2, Kind: "up"}},
+			want:  []int64{2, 1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreSession(movies, tallySession(tt.watchlists, tt.votes), tt.predictions)
+			assert.Equal(t, tt.want, sessionMovieIDs(got))
+		})
+	}
+}
+
+func TestScoreSession_Score(t *testing.T) {
+	movies := []models.Movie{{ID: 1, RatingKinopoisk: 7.5}}
+	votes := []models.SessionVote{{UserID: 1, MovieID: 1, Kind: "up"}}
+	// второй участник не оценивал фильм — берётся рейтинг Кинопоиска
+	got := scoreSession(movies, tallySession(nil, votes), []map[int64]float64{{1: 9.5}, {}})
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, 8.5, got[0].PredictedRating)
+		assert.Equal(t, 7.5, got[0].MinPredicted)
+		// 0.7*8.5 + 0.3*7.5 + 0.5*1
+		assert.Equal(t, 8.7, got[0].Score)
+		assert.Equal(t, 1, got[0].Upvotes)
+	}
+
+	got = scoreSession(movies, tallySession(nil, nil), nil)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, 0.0, got[0].MinPredicted)
+		assert.Equal(t, 0.0, got[0].Score)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// --- Tokens ---
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrUserDisabled        = newError(ErrForbidden, "user is disabled")
+	ErrInvalidRefreshToken = newError(ErrUnauthorized, "invalid refresh token")
+	ErrRefreshTokenReused  = newError(ErrUnauthorized, "refresh token reuse detected, session revoked")
+)
+
+// RefreshTokens обменивает refresh-токен на новую пару токенов (ротация).
+// Повторное предъявление уже использованного токена считается кражей:
+// вся сессия отзывается.
+func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	hash := hashToken(refreshToken)
+	stored, err := s.repo.GetRefreshToken(ctx, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stored.SessionRevoked || stored.Expired {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.Used {
+		return nil, s.revokeReusedSession(ctx, stored)
+	}
+	if stored.UserDisabled {
+		return nil, ErrUserDisabled
+	}
+
+	next, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.repo.RotateRefreshToken(ctx, hash, hashToken(next), stored.SessionID, refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.revokeReusedSession(ctx, stored)
+	}
+	// роль берётся из БД, так что её изменение вступает в силу при следующем обновлении
+	return s.issueTokens(stored.UserID, stored.Role, stored.SessionID, next)
+}
+
+func (s *Service) revokeReusedSession(ctx context.Context, t *models.RefreshToken) error {
+	if err := s.repo.RevokeAuthSession(ctx, t.UserID, t.SessionID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout завершает текущую сессию и отзывает предъявленный access-токен
+func (s *Service) Logout(ctx context.Context, userID int64, sessionID, jti string, expiresAt time.Time) error {
+	if sessionID != "" {
+		if err := s.repo.RevokeAuthSession(ctx, userID, sessionID); err != nil {
+			return err
+		}
+	}
+	return s.revokeAccessToken(ctx, jti, expiresAt)
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах
+func (s *Service) LogoutAll(ctx context.Context, userID int64, jti string, expiresAt time.Time) error {
+	if err := s.repo.RevokeAllAuthSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.revokeAccessToken(ctx, jti, expiresAt)
+}
+
+func (s *Service) revokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return s.repo.RevokeAccessToken(ctx, jti, expiresAt)
+}
+
+// IsTokenRevoked сообщает middleware, отозван ли access-токен
+func (s *Service) IsTokenRevoked(ctx context.Context, jti, sessionID string) (bool, error) {
+	return s.repo.IsAccessTokenRevoked(ctx, jti, sessionID)
+}
+
+// issueTokens подписывает access-токен для сессии и собирает ответ
+func (s *Service) issueTokens(userID int64, role, sessionID, refreshToken string) (*models.TokenPair, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"sid":     sessionID,
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"exp":     now.Add(accessTokenTTL).Unix(),
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenPair{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// randomToken — случайный токен для refresh-токенов и ссылок из писем
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken — в БД refresh-токены хранятся только в виде SHA-256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/repository"
+)
+
+// --- User reviews ---
+
+var (
+	ErrReviewExists       = newError(ErrConflict, "review already exists, use PUT to edit it")
+	ErrReviewNotRated     = invalid("rating", "is required: the movie is not rated yet")
+	ErrOwnReview          = newError(ErrConflict, "cannot vote for or report own review")
+	ErrReviewHidden       = newError(ErrForbidden, "review was hidden by a moderator")
+	ErrInvalidReviewSort  = invalid("sort", "must be one of: helpful, newest")
+	ErrInvalidQueueStatus = invalid("status", "must be one of: reported, hidden")
+)
+
+// GetUserReview возвращает рецензию пользователя на фильм, в том числе скрытую
+func (s *Service) GetUserReview(ctx context.Context, userID, movieID int64) (*models.UserReview, error) {
+	review, err := s.repo.GetUserReview(ctx, userID, movieID)
+	if err != nil {
+		return nil, notFound(err, "review not found")
+	}
+	return review, nil
+}
+
+// CreateUserReview публикует рецензию пользователя. Рецензия привязана к
+// оценке: Rating > 0 ставит или меняет её, 0 — оставляет прежнюю.
+func (s *Service) CreateUserReview(ctx context.Context, review *models.UserReview) (*models.UserReview, error) {
+	if err := s.ensureMovie(ctx, review.MovieID); err != nil {
+		return nil, err
+	}
+	switch err := s.repo.CreateUserReview(ctx, review); {
+	case errors.Is(err, repository.ErrNotRated):
+		return nil, ErrReviewNotRated
+	case errors.Is(err, repository.ErrDuplicate):
+		return nil, ErrReviewExists
+	case errors.Is(err, repository.ErrReviewHidden):
+		return nil, ErrReviewHidden
+	case err != nil:
+		return nil, err
+	}
+	return s.GetUserReview(ctx, review.UserID, review.MovieID)
+}
+
+// UpdateUserReview правит рецензию; Rating > 0 заодно меняет оценку.
+// Скрытая модератором рецензия остаётся скрытой.
+func (s *Service) UpdateUserReview(ctx context.Context, review *models.UserReview) (*models.UserReview, error) {
+	if err := s.repo.UpdateUserReview(ctx, review); err != nil {
+		return nil, notFound(err, "review not found")
+	}
+	return s.GetUserReview(ctx, review.UserID, review.MovieID)
+}
+
+// DeleteUserReview удаляет рецензию пользователя, оставляя его оценку
+func (s *Service) DeleteUserReview(ctx context.Context, userID, movieID int64) error {
+	return notFound(s.repo.DeleteUserReview(ctx, userID, movieID), "review not found")
+}
+
+// ListUserReviews возвращает опубликованные рецензии пользователей на фильм
+// постранично; sort — helpful (по умолчанию) или newest
+func (s *Service) ListUserReviews(ctx context.Context, movieID int64, sort string, page, size int) (*models.UserReviewList, error) {
+	if sort == "" {
+		sort = repository.ReviewsByHelpful
+	}
+	if !repository.IsValidUserReviewSort(sort) {
+		return nil, ErrInvalidReviewSort
+	}
+	exists, err := s.repo.MovieExists(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, newError(ErrNotFound, "movie not found")
+	}
+	page, size = normalizePage(page, size)
+	reviews, total, err := s.repo.ListUserReviews(ctx, movieID, sort, (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []models.UserReview{}
+	}
+	return &models.UserReviewList{
+		Items:      reviews,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+	}, nil
+}
+
+// VoteUserReview отмечает рецензию полезной или бесполезной; повторный голос
+// заменяет прежний
+func (s *Service) VoteUserReview(ctx context.Context, voterID, authorID, movieID int64, helpful bool) error {
+	if voterID == authorID {
+		return ErrOwnReview
+	}
+	return notFound(s.repo.VoteUserReview(ctx, voterID, authorID, movieID, helpful), "review not found")
+}
+
+// DeleteReviewVote отзывает голос за рецензию
+func (s *Service) DeleteReviewVote(ctx context.Context, voterID, authorID, movieID int64) error {
+	return s.repo.DeleteReviewVote(ctx, voterID, authorID, movieID)
+}
+
+// ReportUserReview жалуется на рецензию модераторам
+func (s *Service) ReportUserReview(ctx context.Context, reporterID, authorID, movieID int64, reason string) error {
+	if reporterID == authorID {
+		return ErrOwnReview
+	}
+	return notFound(s.repo.ReportUserReview(ctx, reporterID, authorID, movieID, reason), "review not found")
+}
+
+// Выборки очереди модерации рецензий
+const (
+	QueueReported = "reported" // опубликованные рецензии с жалобами
+	QueueHidden   = "hidden"   // скрытые модераторами
+)
+
+// ListReviewQueue возвращает очередь модерации рецензий постранично
+func (s *Service) ListReviewQueue(ctx context.Context, queue string, page, size int) (*models.ReportedReviewList, error) {
+	var status string
+	switch queue {
+	case "", QueueReported:
+		status = models.ReviewPublished
+	case QueueHidden:
+		status = models.ReviewHidden
+	default:
+		return nil, ErrInvalidQueueStatus
+	}
+	page, size = normalizePage(page, size)
+	reviews, total, err := s.repo.ListModerationReviews(ctx, status, (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []models.ReportedReview{}
+	}
+	return &models.ReportedReviewList{
+		Items:      reviews,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+	}, nil
+}
+
+// HideUserReview скрывает рецензию из выдачи; жалобы на неё сохраняются
+func (s *Service) HideUserReview(ctx context.Context, authorID, movieID int64) (*models.UserReview, error) {
+	return s.setUserReviewStatus(ctx, authorID, movieID, models.ReviewHidden)
+}
+
+// RestoreUserReview возвращает рецензию в выдачу и отклоняет жалобы на неё
+func (s *Service) RestoreUserReview(ctx context.Context, authorID, movieID int64) (*models.UserReview, error) {
+	return s.setUserReviewStatus(ctx, authorID, movieID, models.ReviewPublished)
+}
+
+func (s *Service) setUserReviewStatus(ctx context.Context, authorID, movieID int64, status string) (*models.UserReview, error) {
+	if err := s.repo.SetUserReviewStatus(ctx, authorID, movieID, status); err != nil {
+		return nil, notFound(err, "review not found")
+	}
+	return s.GetUserReview(ctx, authorID, movieID)
+}
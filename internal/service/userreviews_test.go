@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserReviews_Guards(t *testing.T) {
+	// проверки до обращения к БД
+	s := &Service{}
+	ctx := context.Background()
+
+	_, err := s.ListUserReviews(ctx, 301, "rating", 1, 20)
+	assert.Equal(t, ErrInvalidReviewSort, err)
+
+	_, err = s.ListReviewQueue(ctx, "deleted", 1, 20)
+	assert.Equal(t, ErrInvalidQueueStatus, err)
+
+	assert.Equal(t, ErrOwnReview, s.VoteUserReview(ctx, 7, 7, 301, true))
+	assert.Equal(t, ErrOwnReview, s.ReportUserReview(ctx, 7, 7, 301, "самореклама"))
+}
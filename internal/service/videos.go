@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/internal/repository"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
+)
+
+// --- Videos ---
+
+// Сроки свежести сохранённых видео по умолчанию
+const (
+	DefaultReviewsTTL  = 72 * time.Hour
+	DefaultTrailersTTL = 7 * 24 * time.Hour
+)
+
+// Сколько видео хранить; каждый поиск на YouTube стоит 101 единицу квоты
+const (
+	reviewsPerMovie  = 10
+	trailersPerMovie = 5
+)
+
+// ErrInvalidVideoKind — неизвестный вид видео в фильтре
+var ErrInvalidVideoKind = invalid("kind", "must be one of: trailer, teaser, review")
+
+// videoGroup — группа видео фильма, которая загружается из своих источников
+// и обновляется целиком (repository.VideoGroup*)
+type videoGroup struct {
+	name       string
+	defaultTTL time.Duration
+	fetch      func(s *Service, ctx context.Context, m *models.Movie) ([]models.Video, error)
+}
+
+var (
+	reviewsGroup  = videoGroup{repository.VideoGroupReviews, DefaultReviewsTTL, (*Service).searchReviews}
+	trailersGroup = videoGroup{repository.VideoGroupTrailers, DefaultTrailersTTL, (*Service).fetchTrailers}
+)
+
+type videoKey struct {
+	group   string
+	movieID int64
+}
+
+// videoCache — сроки свежести групп видео, их первая загрузка и обновление
+// в фоне. Нулевое значение готово к работе и использует сроки по умолчанию.
+type videoCache struct {
+	ttl   map[string]time.Duration
+	loads fetchGroup[videoKey, struct{}] // первые загрузки групп, общие для параллельных запросов
+
+	mu         sync.Mutex
+	refreshing map[videoKey]bool
+}
+
+// begin отмечает начало обновления группы видео фильма; false — оно уже идёт
+func (c *videoCache) begin(key videoKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshing[key] {
+		return false
+	}
+	if c.refreshing == nil {
+		c.refreshing = make(map[videoKey]bool)
+	}
+	c.refreshing[key] = true
+	return true
+}
+
+func (c *videoCache) end(key videoKey) {
+	c.mu.Lock()
+	delete(c.refreshing, key)
+	c.mu.Unlock()
+}
+
+// stale сообщает, что видео группы, загруженные в fetchedAt, пора обновить
+func (c *videoCache) stale(g videoGroup, fetchedAt, now time.Time) bool {
+	ttl := c.ttl[g.name]
+	if ttl <= 0 {
+		ttl = g.defaultTTL
+	}
+	return now.Sub(fetchedAt) >= ttl
+}
+
+// SetVideosTTL задаёт сроки свежести сохранённых обзоров (REVIEWS_TTL) и
+// трейлеров (TRAILERS_TTL)
+func (s *Service) SetVideosTTL(reviews, trailers time.Duration) {
+	s.videos.ttl = map[string]time.Duration{
+		repository.VideoGroupReviews:  reviews,
+		repository.VideoGroupTrailers: trailers,
+	}
+}
+
+// GetMovieVideos возвращает трейлеры, тизеры и обзоры фильма; kind — вид
+// видео, пустой — все виды. Если один из источников недоступен, а kind не
+// задан, отдаются видео остальных.
+func (s *Service) GetMovieVideos(ctx context.Context, id int64, kind string) ([]models.Video, error) {
+	var kinds []string
+	var groups []videoGroup
+	switch kind {
+	case "":
+		kinds = []string{models.VideoTrailer, models.VideoTeaser, models.VideoReview}
+		groups = []videoGroup{trailersGroup, reviewsGroup}
+	case models.VideoTrailer, models.VideoTeaser:
+		kinds, groups = []string{kind}, []videoGroup{trailersGroup}
+	case models.VideoReview:
+		kinds, groups = []string{kind}, []videoGroup{reviewsGroup}
+	default:
+		return nil, ErrInvalidVideoKind
+	}
+	m, err := s.GetMovie(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	loaded := 0
+	var unavailable error
+	for _, g := range groups {
+		err := s.loadVideos(ctx, m, g)
+		if errors.Is(err, ErrUnavailable) {
+			unavailable = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return nil, unavailable
+	}
+	return s.movieVideos(ctx, id, kinds)
+}
+
+// movieVideos возвращает сохранённые видео фильма; пустой список — не nil
+func (s *Service) movieVideos(ctx context.Context, id int64, kinds []string) ([]models.Video, error) {
+	videos, err := s.repo.GetMovieVideos(ctx, id, kinds)
+	if err != nil {
+		return nil, err
+	}
+	if videos == nil {
+		videos = []models.Video{}
+	}
+	return videos, nil
+}
+
+// loadVideos следит, чтобы группа видео фильма была в БД. Группа, которая
+// ещё не загружалась, загружается синхронно, причём параллельные запросы
+// ждут одной загрузки и не тратят квоту YouTube повторно; устаревшая —
+// обновляется в фоне, а клиент получает сохранённые видео, даже если
+// источник недоступен.
+func (s *Service) loadVideos(ctx context.Context, m *models.Movie, g videoGroup) error {
+	fetchedAt, err := s.repo.VideosFetchedAt(ctx, m.ID, g.name)
+	if err != nil {
+		return notFound(err, "movie not found")
+	}
+	key := videoKey{g.name, m.ID}
+	if fetchedAt == nil {
+		_, err := s.videos.loads.Do(ctx, key, func() (struct{}, error) {
+			// загрузка доводится до конца для остальных ожидающих, даже если этот клиент ушёл
+			ctx := context.WithoutCancel(ctx)
+			videos, err := g.fetch(s, ctx, m)
+			if err != nil {
+				return struct{}{}, youtubeError(err)
+			}
+			return struct{}{}, s.repo.ReplaceMovieVideos(ctx, m.ID, g.name, videos)
+		})
+		return err
+	}
+	if s.videos.stale(g, *fetchedAt, time.Now()) && s.videos.begin(key) {
+		go func() {
+			defer s.videos.end(key)
+			// клиент получит сохранённые видео, не дожидаясь источников
+			ctx := context.WithoutCancel(ctx)
+			videos, err := g.fetch(s, ctx, m)
+			if err == nil {
+				err = s.repo.ReplaceMovieVideos(ctx, m.ID, g.name, videos)
+			}
+			if err != nil {
+				log.Printf("refresh %s of movie %d: %v", g.name, m.ID, err)
+			}
+		}()
+	}
+	return nil
+}
+
+// fetchTrailers собирает трейлеры и тизеры фильма из Kinopoisk и поиска на
+// YouTube; ролики Kinopoisk идут первыми, повторы по ID пропускаются.
+// Ошибка возвращается, только если недоступны оба источника.
+func (s *Service) fetchTrailers(ctx context.Context, m *models.Movie) ([]models.Video, error) {
+	kpVideos, kpErr := s.kpClient.GetVideos(ctx, m.ID)
+	if errors.Is(kpErr, kinopoisk.ErrNotFound) {
+		kpErr = nil
+	}
+	found, ytErr := s.ytClient.FindTrailers(ctx, movieQuery(m, trailersPerMovie))
+	switch {
+	case kpErr != nil && ytErr != nil:
+		return nil, errors.Join(kpErr, ytErr)
+	case kpErr != nil:
+		log.Printf("kinopoisk videos of movie %d: %v", m.ID, kpErr)
+	case ytErr != nil:
+		log.Printf("youtube trailers of movie %d: %v", m.ID, ytErr)
+	}
+	return mergeVideos(KinopoiskVideos(kpVideos), youtubeVideos(found)), nil
+}
+
+// KinopoiskVideos отбирает из роликов Kinopoisk трейлеры и тизеры, размещённые
+// на YouTube или в плеере Kinopoisk; вид и язык определяются по названию
+func KinopoiskVideos(videos []kinopoisk.Video) []models.Video {
+	out := make([]models.Video, 0, len(videos))
+	for _, v := range videos {
+		name := strings.ToLower(v.Name)
+		var kind string
+		switch {
+		case strings.Contains(name, "тизер"), strings.Contains(name, "teaser"):
+			kind = models.VideoTeaser
+		case strings.Contains(name, "трейлер"), strings.Contains(name, "trailer"):
+			kind = models.VideoTrailer
+		default:
+			continue // фрагменты, ТВ-ролики и прочее
+		}
+		var lang string
+		switch {
+		case strings.Contains(name, "русск"), strings.Contains(name, "дублир"):
+			lang = "ru"
+		case strings.Contains(name, "англ"):
+			lang = "en"
+		}
+
+		video := models.Video{Title: v.Name, Kind: kind, Source: models.SourceKinopoisk, Language: lang}
+		if id := v.YouTubeID(); id != "" {
+			video.VideoID = id
+			video.VideoURL = "https://www.youtube.com/watch?v=" + id
+			video.ThumbnailURL = "https://i.ytimg.com/vi/" + id + "/hqdefault.jpg"
+		} else if id := v.WidgetID(); id != "" {
+			video.VideoID = "kp-" + id
+			video.VideoURL = v.URL
+		} else {
+			continue
+		}
+		out = append(out, video)
+	}
+	return out
+}
+
+// youtubeVideos переводит найденные на YouTube ролики в видео фильма
+func youtubeVideos(found []youtube.Video) []models.Video {
+	out := make([]models.Video, 0, len(found))
+	for _, v := range found {
+		video := models.Video{
+			VideoID:      v.VideoID,
+			VideoURL:     v.VideoURL,
+			Title:        v.Title,
+			ChannelTitle: v.ChannelTitle,
+			ThumbnailURL: v.ThumbnailURL,
+			Kind:         v.Kind,
+			Source:       models.SourceYouTube,
+			Language:     v.Language,
+			Duration:     int(v.Duration / time.Second),
+		}
+		if !v.PublishedAt.IsZero() {
+			published := v.PublishedAt
+			video.PublishedAt = &published
+		}
+		out = append(out, video)
+	}
+	return out
+}
+
+// mergeVideos объединяет списки видео, оставляя первое вхождение каждого ID
+func mergeVideos(lists ...[]models.Video) []models.Video {
+	var out []models.Video
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, v := range list {
+			if !seen[v.VideoID] {
+				seen[v.VideoID] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// movieQuery описывает фильм для поиска видео на YouTube
+func movieQuery(m *models.Movie, maxResults int) youtube.MovieQuery {
+	q := youtube.MovieQuery{
+		Title:      m.Title,
+		Year:       m.Year,
+		Runtime:    time.Duration(m.Runtime) * time.Minute,
+		MaxResults: maxResults,
+	}
+	for _, t := range []string{m.OriginalTitle, m.TitleEn} {
+		if t != "" && t != m.Title && !slices.Contains(q.OtherTitles, t) {
+			q.OtherTitles = append(q.OtherTitles, t)
+		}
+	}
+	return q
+}
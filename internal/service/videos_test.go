@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AlexKeyyyy/movies-picker/internal/models"
+	"github.com/AlexKeyyyy/movies-picker/pkg/kinopoisk"
+	"github.com/AlexKeyyyy/movies-picker/pkg/youtube"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVideoCache_Stale(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	var c videoCache
+	assert.False(t, c.stale(reviewsGroup, now.Add(-DefaultReviewsTTL+time.Minute), now))
+	assert.True(t, c.stale(reviewsGroup, now.Add(-DefaultReviewsTTL), now))
+	assert.False(t, c.stale(trailersGroup, now.Add(-DefaultReviewsTTL), now))
+
+	s := &Service{}
+	s.SetVideosTTL(time.Hour, 0)
+	assert.False(t, s.videos.stale(reviewsGroup, now.Add(-30*time.Minute), now))
+	assert.True(t, s.videos.stale(reviewsGroup, now.Add(-2*time.Hour), now))
+	assert.False(t, s.videos.stale(trailersGroup, now.Add(-2*time.Hour), now), "0 means the default TTL")
+}
+
+func TestVideoCache_BeginOnce(t *testing.T) {
+	var c videoCache
+	reviews := videoKey{reviewsGroup.name, 301}
+	assert.True(t, c.begin(reviews))
+	assert.False(t, c.begin(reviews), "refresh already running")
+	assert.True(t, c.begin(videoKey{trailersGroup.name, 301}))
+	assert.True(t, c.begin(videoKey{reviewsGroup.name, 302}))
+
+	c.end(reviews)
+	assert.True(t, c.begin(reviews))
+}
+
+func TestVideoCache_LoadsOnce(t *testing.T) {
+	var c videoCache
+	var calls int32
+	release := make(chan struct{})
+	load := func() (struct{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return struct{}{}, nil
+	}
+
+	// страница фильма запрашивает видео и рецензии, несколько зрителей — одновременно
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.loads.Do(context.Background(), videoKey{reviewsGroup.name, 301}, load)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.loads.Do(context.Background(), videoKey{trailersGroup.name, 301}, load)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "one search per group")
+}
+
+func TestMovieQuery(t *testing.T) {
+	q := movieQuery(&models.Movie{Title: "Матрица", OriginalTitle: "The Matrix", TitleEn: "The Matrix", Year: 1999, Runtime: 136}, 10)
+	assert.Equal(t, youtube.MovieQuery{
+		Title:       "Матрица",
+		OtherTitles: []string{"The Matrix"},
+		Year:        1999,
+		Runtime:     136 * time.Minute,
+		MaxResults:  10,
+	}, q)
+
+	assert.Empty(t, movieQuery(&models.Movie{Title: "Up", OriginalTitle: "Up"}, 5).OtherTitles)
+}
+
+func TestKinopoiskVideos(t *testing.T) {
+	videos := KinopoiskVideos([]kinopoisk.Video{
+		{URL: "https://www.youtube.com/v/vKQi3bBA1y8", Name: "Трейлер (русский язык)", Site: kinopoisk.SiteYouTube},
+		{URL: "https://youtu.be/m8e-FF8MsqU", Name: "Тизер (английский язык)", Site: kinopoisk.SiteYouTube},
+		{URL: "https://widgets.kinopoisk.ru/discovery/trailer/16871?onlyPlayer=1", Name: "Трейлер (дублированный)", Site: kinopoisk.SiteKinopoiskWidget},
+		{URL: "https://www.youtube.com/v/aaaaaaaaaaa", Name: "Фрагмент", Site: kinopoisk.SiteYouTube},
+		{URL: "https://disk.yandex.ru/i/abcdef", Name: "Трейлер", Site: kinopoisk.SiteYandexDisk},
+	})
+	assert.Equal(t, []models.Video{
+		{
+			VideoID: "vKQi3bBA1y8", VideoURL: "https://www.youtube.com/watch?v=vKQi3bBA1y8", Title: "Трейлер (русский язык)",
+			ThumbnailURL: "https://i.ytimg.com/vi/vKQi3bBA1y8/hqdefault.jpg", Kind: "trailer", Source: "kinopoisk", Language: "ru",
+		},
+		{
+			VideoID: "m8e-FF8MsqU", VideoURL: "https://www.youtube.com/watch?v=m8e-FF8MsqU", Title: "Тизер (английский язык)",
+			ThumbnailURL: "https://i.ytimg.com/vi/m8e-FF8MsqU/hqdefault.jpg", Kind: "teaser", Source: "kinopoisk", Language: "en",
+		},
+		{
+			VideoID: "kp-16871", VideoURL: "https://widgets.kinopoisk.ru/discovery/trailer/16871?onlyPlayer=1", Title: "Трейлер (дублированный)",
+			Kind: "trailer", Source: "kinopoisk", Language: "ru",
+		},
+	}, videos)
+}
+
+func TestYoutubeVideos(t *testing.T) {
+	published := time.Date(2019, 3, 31, 10, 0, 0, 0, time.UTC)
+	videos := youtubeVideos([]youtube.Video{
+		{VideoID: "abc", Title: "Обзор", Kind: youtube.KindReview, Language: "ru", PublishedAt: published, Duration: 12*time.Minute + 30*time.Second},
+		{VideoID: "def", Title: "Трейлер", Kind: youtube.KindTrailer},
+	})
+	if assert.Len(t, videos, 2) {
+		assert.Equal(t, 750, videos[0].Duration)
+		assert.Equal(t, models.SourceYouTube, videos[0].Source)
+		assert.Equal(t, &published, videos[0].PublishedAt)
+		assert.Nil(t, videos[1].PublishedAt)
+		assert.Equal(t, models.VideoTrailer, videos[1].Kind)
+	}
+}
+
+func TestMergeVideos(t *testing.T) {
+	merged := mergeVideos(
+		[]models.Video{{VideoID: "a", Source: "kinopoisk"}, {VideoID: "b", Source: "kinopoisk"}},
+		[]models.Video{{VideoID: "b", Source: "youtube"}, {VideoID: "c", Source: "youtube"}},
+	)
+	assert.Equal(t, []models.Video{
+		{VideoID: "a", Source: "kinopoisk"},
+		{VideoID: "b", Source: "kinopoisk"},
+		{VideoID: "c", Source: "youtube"},
+	}, merged)
+}
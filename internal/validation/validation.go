@@ -0,0 +1,121 @@
+// Package validation проверяет входные данные запросов и собирает ошибки по полям.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// MinPasswordLength — минимальная длина нового пароля
+const MinPasswordLength = 8
+
+// FieldError — ошибка в конкретном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors — ошибки валидации по полям
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validatable — тело запроса, которое умеет проверять себя
+type Validatable interface {
+	Validate() error
+}
+
+// Validator накапливает ошибки; для каждого поля сохраняется только первая
+type Validator struct {
+	errs Errors
+}
+
+// Add добавляет ошибку поля, если для него ещё нет ошибки
+func (v *Validator) Add(field, message string) {
+	for _, fe := range v.errs {
+		if fe.Field == field {
+			return
+		}
+	}
+	v.errs = append(v.errs, FieldError{Field: field, Message: message})
+}
+
+// Check добавляет ошибку, если ok ложно
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.Add(field, message)
+	}
+}
+
+// Required проверяет, что строка не пустая
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// Email проверяет формат непустого адреса
+func (v *Validator) Email(field, value string) {
+	if value != "" {
+		v.Check(IsEmail(value), field, "must be a valid email address")
+	}
+}
+
+// Password проверяет длину непустого нового пароля
+func (v *Validator) Password(field, value string) {
+	if value != "" {
+		v.Check(utf8.RuneCountInString(value) >= MinPasswordLength, field,
+			fmt.Sprintf("must be at least %d characters long", MinPasswordLength))
+	}
+}
+
+// MaxLength ограничивает длину строки в символах
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field,
+		fmt.Sprintf("must be at most %d characters long", max))
+}
+
+// Range проверяет, что число лежит в [min, max]
+func (v *Validator) Range(field string, value, min, max int) {
+	v.Check(value >= min && value <= max, field, fmt.Sprintf("must be between %d and %d", min, max))
+}
+
+// ID проверяет, что идентификатор передан и положителен
+func (v *Validator) ID(field string, id int64) {
+	v.Check(id > 0, field, "must be a positive integer")
+}
+
+// OneOf проверяет, что значение входит в список допустимых
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.Add(field, "must be one of: "+strings.Join(allowed, ", "))
+}
+
+// Valid сообщает, что ошибок нет
+func (v *Validator) Valid() bool {
+	return len(v.errs) == 0
+}
+
+// Err возвращает накопленные ошибки как Errors или nil
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return v.errs
+}
+
+// IsEmail проверяет, что строка — голый адрес вида user@host.tld
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndex(s, "@")+1:], ".")
+}
@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	var v Validator
+	v.Required("email", "")
+	v.Email("email", "not-an-email") // у поля уже есть ошибка
+	v.Password("password", "abc")
+	v.Range("rating", 99, 1, 10)
+	v.ID("movie_id", 0)
+	v.OneOf("kind", "like", "up", "down", "veto")
+	v.MaxLength("title", "ok", 10)
+
+	err := v.Err()
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, Errors{
+		{Field: "email", Message: "is required"},
+		{Field: "password", Message: "must be at least 8 characters long"},
+		{Field: "rating", Message: "must be between 1 and 10"},
+		{Field: "movie_id", Message: "must be a positive integer"},
+		{Field: "kind", Message: "must be one of: up, down, veto"},
+	}, errs)
+}
+
+func TestValidator_Valid(t *testing.T) {
+	var v Validator
+	v.Required("email", "user@example.com")
+	v.Email("email", "user@example.com")
+	v.Password("password", "") // пустой пароль не меняется
+	v.Range("rating", 10, 1, 10)
+	assert.NoError(t, v.Err())
+}
+
+func TestIsEmail(t *testing.T) {
+	for in, want := range map[string]bool{
+		"user@example.com":        true,
+		"":                        false,
+		"not-an-email":            false,
+		"user@localhost":          false,
+		"User <user@example.com>": false,
+		" user@example.com":       false,
+	} {
+		assert.Equal(t, want, IsEmail(in), in)
+	}
+}
@@ -1,10 +1,16 @@
 package kinopoisk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,89 +18,311 @@ type Client struct {
 	httpClient *http.Client
 	apiKey     string
 	baseURL    string
+	usage      usage
+	retry      RetryPolicy
+	limiter    *limiter      // nil — без ограничения частоты
+	dailyQuota int           // 0 — квоту сообщает только API ответом 402
+	opTimeout  time.Duration // дедлайн вызова метода вместе с повторами; 0 — без него
+}
+
+// Options — настройки устойчивости клиента
+type Options struct {
+	Timeout    time.Duration // таймаут одной попытки
+	OpTimeout  time.Duration // дедлайн вызова метода вместе с повторами и ожиданием лимита
+	Retry      RetryPolicy
+	RateLimit  float64 // запросов в секунду; 0 — без ограничения
+	Burst      int     // сколько запросов можно сделать подряд без паузы
+	DailyQuota int     // запросов в сутки; 0 — без собственного учёта квоты
+}
+
+// DefaultOptions — настройки NewClient. API допускает до 20 запросов в секунду
+// на ключ; оставляем запас для других процессов с тем же ключом.
+var DefaultOptions = Options{
+	Timeout:   10 * time.Second,
+	OpTimeout: 30 * time.Second,
+	Retry:     DefaultRetryPolicy,
+	RateLimit: 10,
+	Burst:     10,
+}
+
+// usage считает запросы к API за текущие сутки (UTC): у ключа Kinopoisk суточная квота
+type usage struct {
+	mu        sync.Mutex
+	day       string
+	count     int
+	exhausted bool // API уже ответил 402 в эти сутки
+}
+
+// rollover обнуляет счётчик в начале новых суток; вызывается под mu
+func (u *usage) rollover() {
+	if today := time.Now().UTC().Format("2006-01-02"); u.day != today {
+		u.day, u.count, u.exhausted = today, 0, false
+	}
+}
+
+// take учитывает запрос; false — суточная квота исчерпана
+func (u *usage) take(quota int) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollover()
+	if u.exhausted || (quota > 0 && u.count >= quota) {
+		return false
+	}
+	u.count++
+	return true
+}
+
+// exhaust отмечает, что API сообщил об исчерпанной квоте
+func (u *usage) exhaust() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollover()
+	u.exhausted = true
+}
+
+func (u *usage) today() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollover()
+	return u.count
+}
+
+// RequestsToday возвращает число запросов к API, сделанных процессом за текущие сутки
+func (c *Client) RequestsToday() int {
+	return c.usage.today()
 }
 
 func NewClient(apiKey string) *Client {
-	return &Client{
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+	return NewClientWithOptions(apiKey, DefaultOptions)
+}
+
+func NewClientWithOptions(apiKey string, opts Options) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: opts.Timeout},
 		apiKey:     apiKey,
-		baseURL:    "https://kinopoiskapiunofficial.tech/api/v2.2",
+		baseURL:    "https://kinopoiskapiunofficial.tech/api/" + apiVersion,
+		retry:      opts.Retry,
+		dailyQuota: opts.DailyQuota,
+		opTimeout:  opts.OpTimeout,
+	}
+	if opts.RateLimit > 0 {
+		c.limiter = newLimiter(opts.RateLimit, opts.Burst)
 	}
+	return c
 }
 
-type Film struct {
-	KinopoiskID     int64       `json:"kinopoiskId"`
-	NameRu          string      `json:"nameRu"`
-	NameEn          string      `json:"nameEn"`
-	Year            json.Number `json:"year"`
-	PosterURL       string      `json:"posterUrl"`
-	Description     string      `json:"description"`
-	RatingKinopoisk float64     `json:"ratingKinopoisk"`
+// apiVersion — версия API в baseURL; часть методов есть только в других версиях
+const apiVersion = "v2.2"
+
+// get выполняет GET-запрос к методу основной версии API
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	return c.getURL(ctx, c.baseURL+path, out)
 }
 
-type CollectionsResponse struct {
-	Total      int    `json:"total"`
-	TotalPages int    `json:"totalPages"`
-	Items      []Film `json:"items"`
+// getVersion выполняет GET-запрос к методу другой версии API (v1, v2.1)
+func (c *Client) getVersion(ctx context.Context, version, path string, out interface{}) error {
+	root := strings.TrimSuffix(c.baseURL, "/"+apiVersion)
+	return c.getURL(ctx, root+"/"+version+path, out)
 }
 
-// GetPopularAll получает одну страницу популярного списка
-func (c *Client) GetPopularAll(page int) ([]Film, int, error) {
-	url := fmt.Sprintf("%s/films/collections?type=TOP_POPULAR_ALL&page=%d", c.baseURL, page)
-	req, err := http.NewRequest("GET", url, nil)
+// getURL выполняет GET-запрос с повторами и декодирует ответ в out
+func (c *Client) getURL(ctx context.Context, rawURL string, out interface{}) error {
+	if c.opTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opTimeout)
+		defer cancel()
+	}
+	for attempt := 1; ; attempt++ {
+		err := c.getOnce(ctx, rawURL, out)
+		if err == nil || attempt >= c.retry.MaxAttempts || !temporary(err) || ctx.Err() != nil {
+			return err
+		}
+		wait := c.retry.backoff(attempt)
+		var se *StatusError
+		if errors.As(err, &se) && se.RetryAfter > wait {
+			if c.retry.MaxDelay > 0 && se.RetryAfter > c.retry.MaxDelay {
+				return err
+			}
+			wait = se.RetryAfter
+		}
+		if sleep(ctx, wait) != nil {
+			return err
+		}
+	}
+}
+
+// getOnce — одна попытка запроса
+func (c *Client) getOnce(ctx context.Context, rawURL string, out interface{}) error {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
 	req.Header.Set("X-API-KEY", c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
+	if !c.usage.take(c.dailyQuota) {
+		return ErrQuotaExceeded
+	}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		se := &StatusError{StatusCode: resp.StatusCode}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			se.RetryAfter = time.Duration(secs) * time.Second
+		}
+		if resp.StatusCode == http.StatusPaymentRequired {
+			c.usage.exhaust()
+		}
+		return se
 	}
 
 	dec := json.NewDecoder(resp.Body)
 	dec.UseNumber()
+	return dec.Decode(out)
+}
+
+// temporary — ошибка сети или временный отказ API, после которых стоит повторить запрос
+func temporary(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.temporary()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+type Film struct {
+	KinopoiskID     int64       `json:"kinopoiskId"`
+	ImdbID          string      `json:"imdbId"`
+	NameRu          string      `json:"nameRu"`
+	NameEn          string      `json:"nameEn"`
+	NameOriginal    string      `json:"nameOriginal"`
+	Year            json.Number `json:"year"`
+	PosterURL       string      `json:"posterUrl"`
+	Description     string      `json:"description"`
+	RatingKinopoisk float64     `json:"ratingKinopoisk"`
+	RatingImdb      float64     `json:"ratingImdb"`
+	FilmLength      int         `json:"filmLength"` // минуты
+	RatingAgeLimits string      `json:"ratingAgeLimits"`
+	Type            string      `json:"type"`
+	Genres          []Genre     `json:"genres"`
+	Countries       []Country   `json:"countries"`
+
+	// подробности, которые отдаёт только GetFilm (в подборках и поиске их нет)
+	PosterURLPreview         string `json:"posterUrlPreview"`
+	CoverURL                 string `json:"coverUrl"`
+	LogoURL                  string `json:"logoUrl"`
+	WebURL                   string `json:"webUrl"`
+	Slogan                   string `json:"slogan"`
+	ShortDescription         string `json:"shortDescription"`
+	RatingKinopoiskVoteCount int    `json:"ratingKinopoiskVoteCount"`
+	RatingImdbVoteCount      int    `json:"ratingImdbVoteCount"`
+	RatingMpaa               string `json:"ratingMpaa"`
+	StartYear                int    `json:"startYear"` // для сериалов
+	EndYear                  int    `json:"endYear"`
+	Serial                   bool   `json:"serial"`
+	ShortFilm                bool   `json:"shortFilm"`
+	Completed                bool   `json:"completed"`
+}
+
+type Genre struct {
+	Genre string `json:"genre"`
+}
+
+type Country struct {
+	Country string `json:"country"`
+}
+
+// GenreNames возвращает названия жанров фильма
+func (f Film) GenreNames() []string {
+	names := make([]string, 0, len(f.Genres))
+	for _, g := range f.Genres {
+		if g.Genre != "" {
+			names = append(names, g.Genre)
+		}
+	}
+	return names
+}
+
+// CountryNames возвращает названия стран фильма
+func (f Film) CountryNames() []string {
+	names := make([]string, 0, len(f.Countries))
+	for _, c := range f.Countries {
+		if c.Country != "" {
+			names = append(names, c.Country)
+		}
+	}
+	return names
+}
+
+// PageSize — число фильмов на одной странице ответа Kinopoisk API
+const PageSize = 20
+
+type CollectionsResponse struct {
+	Total      int    `json:"total"`
+	TotalPages int    `json:"totalPages"`
+	Items      []Film `json:"items"`
+}
+
+// Типы подборок Kinopoisk API
+const (
+	CollectionPopularAll = "TOP_POPULAR_ALL"
+	CollectionTop250     = "TOP_250_MOVIES"
+)
 
+// GetPopularAll получает одну страницу популярного списка
+func (c *Client) GetPopularAll(ctx context.Context, page int) ([]Film, int, error) {
+	return c.GetCollection(ctx, CollectionPopularAll, page)
+}
+
+// GetCollection получает одну страницу подборки вместе с числом страниц
+func (c *Client) GetCollection(ctx context.Context, collection string, page int) ([]Film, int, error) {
 	var cr CollectionsResponse
-	if err := dec.Decode(&cr); err != nil {
+	path := fmt.Sprintf("/films/collections?type=%s&page=%d", url.QueryEscape(collection), page)
+	if err := c.get(ctx, path, &cr); err != nil {
 		return nil, 0, err
 	}
 	return cr.Items, cr.TotalPages, nil
 }
 
 // SearchByKeyword получает одну страницу по ключевому слову
-func (c *Client) SearchByKeyword(keyword string, page int) ([]Film, int, error) {
-	url := fmt.Sprintf("%s/films?type=ALL&ratingFrom=0&ratingTo=10&yearFrom=1000&yearTo=3000&keyword=%s&page=%d",
-		c.baseURL, url.QueryEscape(keyword), page)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, 0, err
-	}
-	req.Header.Set("X-API-KEY", c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+func (c *Client) SearchByKeyword(ctx context.Context, keyword string, page int) ([]Film, int, error) {
+	cr, err := c.SearchByKeywordPage(ctx, keyword, page)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer resp.Body.Close()
+	return cr.Items, cr.TotalPages, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// SearchByKeywordPage получает одну страницу поиска вместе с общим числом результатов
+func (c *Client) SearchByKeywordPage(ctx context.Context, keyword string, page int) (*CollectionsResponse, error) {
+	var cr CollectionsResponse
+	path := fmt.Sprintf("/films?type=ALL&ratingFrom=0&ratingTo=10&yearFrom=1000&yearTo=3000&keyword=%s&page=%d",
+		url.QueryEscape(keyword), page)
+	if err := c.get(ctx, path, &cr); err != nil {
+		return nil, err
 	}
+	return &cr, nil
+}
 
-	dec := json.NewDecoder(resp.Body)
-	dec.UseNumber()
-
-	var cr CollectionsResponse
-	if err := dec.Decode(&cr); err != nil {
-		return nil, 0, err
+// GetFilm получает полную карточку фильма по его Kinopoisk ID;
+// неизвестный фильм — ErrNotFound
+func (c *Client) GetFilm(ctx context.Context, id int64) (*Film, error) {
+	var f Film
+	if err := c.get(ctx, fmt.Sprintf("/films/%d", id), &f); err != nil {
+		return nil, err
 	}
-	return cr.Items, cr.TotalPages, nil
+	return &f, nil
 }
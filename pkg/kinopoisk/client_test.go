@@ -1,7 +1,9 @@
 package kinopoisk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -68,7 +70,7 @@ func TestGetPopularAll_Success(t *testing.T) {
 	}
 
 	// Вызываем тестируемый метод
-	films, totalPages, err := client.GetPopularAll(1)
+	films, totalPages, err := client.GetPopularAll(context.Background(), 1)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -129,7 +131,7 @@ func TestGetPopularAll_ErrorHandling(t *testing.T) {
 				baseURL:    ts.URL,
 			}
 
-			_, _, err := client.GetPopularAll(1)
+			_, _, err := client.GetPopularAll(context.Background(), 1)
 			if err == nil {
 				t.Fatal("Expected error, got nil")
 			}
@@ -181,7 +183,7 @@ func TestSearchByKeyword_Success(t *testing.T) {
 	}
 
 	// Вызываем тестируемый метод
-	films, totalPages, err := client.SearchByKeyword("test", 2)
+	films, totalPages, err := client.SearchByKeyword(context.Background(), "test", 2)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -213,13 +215,116 @@ func TestSearchByKeyword_ErrorHandling(t *testing.T) {
 		baseURL:    ts.URL,
 	}
 
-	_, _, err := client.SearchByKeyword("test", 1)
+	_, _, err := client.SearchByKeyword(context.Background(), "test", 1)
 	if err == nil {
 		t.Fatal("Expected error, got nil")
 	}
 
-	expectedErr := "unexpected status code: 404"
-	if err.Error() != expectedErr {
-		t.Errorf("Expected error '%s', got '%v'", expectedErr, err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got '%v'", err)
+	}
+}
+
+func TestFilm_GenreAndCountryNames(t *testing.T) {
+	raw := `{"kinopoiskId": 301, "nameRu": "Матрица", "nameOriginal": "The Matrix",
+		"genres": [{"genre": "фантастика"}, {"genre": "боевик"}, {"genre": ""}],
+		"countries": [{"country": "США"}], "filmLength": 136, "type": "FILM"}`
+
+	var f Film
+	if err := json.Unmarshal([]byte(raw), &f); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	genres := f.GenreNames()
+	if len(genres) != 2 || genres[0] != "фантастика" || genres[1] != "боевик" {
+		t.Errorf("Unexpected genres: %v", genres)
+	}
+	countries := f.CountryNames()
+	if len(countries) != 1 || countries[0] != "США" {
+		t.Errorf("Unexpected countries: %v", countries)
+	}
+	if f.FilmLength != 136 || f.NameOriginal != "The Matrix" {
+		t.Errorf("Unexpected film details: %+v", f)
+	}
+}
+
+func TestSearchByKeywordPage_Total(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "3" {
+			t.Errorf("Expected page 3, got %s", r.URL.Query().Get("page"))
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"total": 57, "totalPages": 3, "items": [{"kinopoiskId": 1}]}`))
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		apiKey:     "test-api-key",
+		baseURL:    ts.URL,
+	}
+
+	resp, err := client.SearchByKeywordPage(context.Background(), "test", 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Total != 57 || resp.TotalPages != 3 || len(resp.Items) != 1 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestGetFilm(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/films/301":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"kinopoiskId": 301, "nameRu": "Матрица", "year": 1999, "filmLength": 136}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		apiKey:     "test-api-key",
+		baseURL:    ts.URL,
+	}
+
+	f, err := client.GetFilm(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.KinopoiskID != 301 || f.NameRu != "Матрица" || f.FilmLength != 136 {
+		t.Errorf("Unexpected film: %+v", f)
+	}
+
+	if _, err := client.GetFilm(context.Background(), 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if n := client.RequestsToday(); n != 2 {
+		t.Errorf("Expected 2 requests today, got %d", n)
+	}
+}
+
+func TestGetCollection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("type"); got != CollectionTop250 {
+			t.Errorf("Expected type %s, got %s", CollectionTop250, got)
+		}
+		if got := r.URL.Query().Get("page"); got != "2" {
+			t.Errorf("Expected page 2, got %s", got)
+		}
+		json.NewEncoder(w).Encode(CollectionsResponse{TotalPages: 13, Items: []Film{{KinopoiskID: 326}}})
+	}))
+	defer ts.Close()
+
+	client := &Client{httpClient: ts.Client(), apiKey: "test-api-key", baseURL: ts.URL}
+	films, totalPages, err := client.GetCollection(context.Background(), CollectionTop250, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if totalPages != 13 || len(films) != 1 || films[0].KinopoiskID != 326 {
+		t.Errorf("Unexpected result: %d pages, %+v", totalPages, films)
 	}
 }
@@ -0,0 +1,50 @@
+package kinopoisk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Типизированные ошибки API; проверяются через errors.Is
+var (
+	ErrNotFound      = errors.New("kinopoisk: not found")
+	ErrUnauthorized  = errors.New("kinopoisk: invalid or missing API key")
+	ErrQuotaExceeded = errors.New("kinopoisk: request quota exceeded")
+	ErrRateLimited   = errors.New("kinopoisk: too many requests")
+)
+
+// StatusError — неуспешный ответ API. Известные коды сопоставляются с
+// типизированными ошибками: 404 — ErrNotFound, 401/403 — ErrUnauthorized,
+// 402 (исчерпан суточный лимит ключа) — ErrQuotaExceeded, 429 — ErrRateLimited.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // из заголовка Retry-After, если он был
+}
+
+func (e *StatusError) Error() string {
+	if kind := e.Unwrap(); kind != nil {
+		return fmt.Sprintf("%v (status %d)", kind, e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusPaymentRequired:
+		return ErrQuotaExceeded
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// temporary — стоит ли повторить запрос
+func (e *StatusError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
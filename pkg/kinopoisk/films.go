@@ -0,0 +1,240 @@
+package kinopoisk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// --- Staff ---
+
+// Профессии в съёмочной группе (StaffMember.ProfessionKey)
+const (
+	ProfessionDirector = "DIRECTOR"
+	ProfessionActor    = "ACTOR"
+	ProfessionWriter   = "WRITER"
+	ProfessionProducer = "PRODUCER"
+	ProfessionOperator = "OPERATOR"
+	ProfessionComposer = "COMPOSER"
+	ProfessionDesign   = "DESIGN"
+	ProfessionEditor   = "EDITOR"
+)
+
+// StaffMember — участник съёмочной группы фильма
+type StaffMember struct {
+	StaffID        int64  `json:"staffId"`
+	NameRu         string `json:"nameRu"`
+	NameEn         string `json:"nameEn"`
+	Description    string `json:"description"` // у актёров — роль
+	PosterURL      string `json:"posterUrl"`
+	ProfessionText string `json:"professionText"`
+	ProfessionKey  string `json:"professionKey"`
+}
+
+// Name возвращает русское имя, а если его нет — английское
+func (m StaffMember) Name() string {
+	if m.NameRu != "" {
+		return m.NameRu
+	}
+	return m.NameEn
+}
+
+// GetStaff получает режиссёров, актёров и остальную съёмочную группу фильма
+// в порядке, в котором их показывает Kinopoisk
+func (c *Client) GetStaff(ctx context.Context, filmID int64) ([]StaffMember, error) {
+	var staff []StaffMember
+	if err := c.getVersion(ctx, "v1", fmt.Sprintf("/staff?filmId=%d", filmID), &staff); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// --- Related films ---
+
+// Типы связи между фильмами (RelatedFilm.RelationType)
+const (
+	RelationSimilar = "SIMILAR"
+	RelationSequel  = "SEQUEL"
+	RelationPrequel = "PREQUEL"
+	RelationRemake  = "REMAKE"
+)
+
+// RelatedFilm — похожий фильм, сиквел, приквел или ремейк
+type RelatedFilm struct {
+	FilmID           int64  `json:"filmId"`
+	NameRu           string `json:"nameRu"`
+	NameEn           string `json:"nameEn"`
+	NameOriginal     string `json:"nameOriginal"`
+	PosterURL        string `json:"posterUrl"`
+	PosterURLPreview string `json:"posterUrlPreview"`
+	RelationType     string `json:"relationType"`
+}
+
+// GetSimilars получает фильмы, которые Kinopoisk считает похожими
+func (c *Client) GetSimilars(ctx context.Context, filmID int64) ([]RelatedFilm, error) {
+	var resp struct {
+		Total int           `json:"total"`
+		Items []RelatedFilm `json:"items"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/films/%d/similars", filmID), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetSequelsAndPrequels получает сиквелы, приквелы и ремейки фильма
+func (c *Client) GetSequelsAndPrequels(ctx context.Context, filmID int64) ([]RelatedFilm, error) {
+	var films []RelatedFilm
+	if err := c.getVersion(ctx, "v2.1", fmt.Sprintf("/films/%d/sequels_and_prequels", filmID), &films); err != nil {
+		return nil, err
+	}
+	return films, nil
+}
+
+// --- Facts and box office ---
+
+// Fact — интересный факт о фильме или киноляп
+type Fact struct {
+	Text    string `json:"text"` // может содержать HTML-ссылки Kinopoisk
+	Type    string `json:"type"` // FACT или BLOOPER
+	Spoiler bool   `json:"spoiler"`
+}
+
+// GetFacts получает факты и киноляпы фильма
+func (c *Client) GetFacts(ctx context.Context, filmID int64) ([]Fact, error) {
+	var resp struct {
+		Total int    `json:"total"`
+		Items []Fact `json:"items"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/films/%d/facts", filmID), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// Статьи бюджета и сборов (BoxOffice.Type)
+const (
+	BoxOfficeBudget    = "BUDGET"
+	BoxOfficeMarketing = "MARKETING"
+	BoxOfficeUSA       = "USA"
+	BoxOfficeRussia    = "RUS"
+	BoxOfficeWorld     = "WORLD"
+)
+
+// BoxOffice — бюджет фильма или сборы в одном регионе
+type BoxOffice struct {
+	Type         string `json:"type"`
+	Amount       int64  `json:"amount"`
+	CurrencyCode string `json:"currencyCode"`
+	Name         string `json:"name"`
+	Symbol       string `json:"symbol"`
+}
+
+// GetBoxOffice получает бюджет и кассовые сборы фильма
+func (c *Client) GetBoxOffice(ctx context.Context, filmID int64) ([]BoxOffice, error) {
+	var resp struct {
+		Total int         `json:"total"`
+		Items []BoxOffice `json:"items"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/films/%d/box_office", filmID), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// --- Videos ---
+
+// Площадки, на которых размещены видео (Video.Site)
+const (
+	SiteYouTube         = "YOUTUBE"
+	SiteKinopoiskWidget = "KINOPOISK_WIDGET"
+	SiteYandexDisk      = "YANDEX_DISK"
+)
+
+// Video — трейлер, тизер или другой ролик о фильме
+type Video struct {
+	URL  string `json:"url"`
+	Name string `json:"name"` // например «Трейлер (русский язык)»
+	Site string `json:"site"`
+}
+
+var (
+	youtubeIDPattern = regexp.MustCompile(`(?:youtube\.com/(?:watch\?v=|v/|embed/)|youtu\.be/)([\w-]{11})`)
+	widgetIDPattern  = regexp.MustCompile(`/trailer/(\d+)`)
+)
+
+// YouTubeID возвращает ID ролика на YouTube или "", если видео размещено не там
+func (v Video) YouTubeID() string {
+	if m := youtubeIDPattern.FindStringSubmatch(v.URL); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// WidgetID возвращает номер трейлера в плеере Kinopoisk или ""
+func (v Video) WidgetID() string {
+	if v.Site != SiteKinopoiskWidget {
+		return ""
+	}
+	if m := widgetIDPattern.FindStringSubmatch(v.URL); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GetVideos получает трейлеры и тизеры фильма
+func (c *Client) GetVideos(ctx context.Context, filmID int64) ([]Video, error) {
+	var resp struct {
+		Total int     `json:"total"`
+		Items []Video `json:"items"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/films/%d/videos", filmID), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// --- Reviews ---
+
+// Тональность рецензии зрителя (Review.Type)
+const (
+	ReviewPositive = "POSITIVE"
+	ReviewNegative = "NEGATIVE"
+	ReviewNeutral  = "NEUTRAL"
+)
+
+// Порядок рецензий в GetReviews
+const (
+	ReviewsByDate        = "DATE_DESC"
+	ReviewsByHelpfulness = "USER_POSITIVE_RATING_DESC"
+)
+
+// Review — рецензия зрителя на Kinopoisk
+type Review struct {
+	KinopoiskID    int64  `json:"kinopoiskId"`
+	Type           string `json:"type"`
+	Date           string `json:"date"`           // 2010-09-05T20:37:00, время московское
+	PositiveRating int    `json:"positiveRating"` // сколько читателей сочли рецензию полезной
+	NegativeRating int    `json:"negativeRating"`
+	Author         string `json:"author"`
+	Title          string `json:"title"`
+	Description    string `json:"description"` // текст рецензии
+}
+
+// ReviewPage — страница рецензий (до 20 на странице)
+type ReviewPage struct {
+	Total      int      `json:"total"`
+	TotalPages int      `json:"totalPages"`
+	Items      []Review `json:"items"`
+}
+
+// GetReviews получает страницу рецензий зрителей на фильм; order —
+// ReviewsByDate, ReviewsByHelpfulness или другой порядок API
+func (c *Client) GetReviews(ctx context.Context, filmID int64, page int, order string) (*ReviewPage, error) {
+	var resp ReviewPage
+	if err := c.get(ctx, fmt.Sprintf("/films/%d/reviews?page=%d&order=%s", filmID, page, url.QueryEscape(order)), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
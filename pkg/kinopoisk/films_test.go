@@ -0,0 +1,189 @@
+package kinopoisk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newFixtureServer отвечает содержимым testdata/<файл> на запросы к путям из routes
+// (ключ — путь с query-строкой), на остальные — 404
+func newFixtureServer(t *testing.T, routes map[string]string) *Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-api-key" {
+			t.Error("Missing or invalid API key header")
+		}
+		file, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(ts.Close)
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		apiKey:     "test-api-key",
+		baseURL:    ts.URL + "/api/v2.2",
+	}
+}
+
+func TestGetFilm_Details(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v2.2/films/301": "film_301.json"})
+
+	f, err := client.GetFilm(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.NameOriginal != "The Matrix" || f.Year.String() != "1999" || f.FilmLength != 136 {
+		t.Errorf("Unexpected film: %+v", f)
+	}
+	if f.Slogan != "Добро пожаловать в реальный мир" || f.RatingKinopoiskVoteCount != 524567 {
+		t.Errorf("Unexpected details: slogan %q, votes %d", f.Slogan, f.RatingKinopoiskVoteCount)
+	}
+	if f.WebURL != "https://www.kinopoisk.ru/film/301/" || f.Serial || f.StartYear != 0 {
+		t.Errorf("Unexpected details: %+v", f)
+	}
+}
+
+func TestGetStaff(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v1/staff?filmId=301": "staff_301.json"})
+
+	staff, err := client.GetStaff(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(staff) != 3 {
+		t.Fatalf("Expected 3 staff members, got %d", len(staff))
+	}
+	if staff[0].ProfessionKey != ProfessionDirector || staff[0].Name() != "Лана Вачовски" {
+		t.Errorf("Unexpected director: %+v", staff[0])
+	}
+	if staff[1].ProfessionKey != ProfessionActor || staff[1].Description != "Neo" {
+		t.Errorf("Unexpected actor: %+v", staff[1])
+	}
+	if staff[2].Name() != "Joel Silver" {
+		t.Errorf("Expected English name fallback, got %q", staff[2].Name())
+	}
+
+	if _, err := client.GetStaff(context.Background(), 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetSimilars(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v2.2/films/301/similars": "similars_301.json"})
+
+	films, err := client.GetSimilars(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(films) != 2 || films[0].FilmID != 444 || films[0].NameOriginal != "Dark City" {
+		t.Errorf("Unexpected similars: %+v", films)
+	}
+	if films[1].RelationType != RelationSimilar {
+		t.Errorf("Expected relation %s, got %s", RelationSimilar, films[1].RelationType)
+	}
+}
+
+func TestGetSequelsAndPrequels(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v2.1/films/301/sequels_and_prequels": "sequels_301.json"})
+
+	films, err := client.GetSequelsAndPrequels(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(films) != 2 || films[0].FilmID != 298 || films[0].RelationType != RelationSequel {
+		t.Errorf("Unexpected sequels: %+v", films)
+	}
+}
+
+func TestGetFacts(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v2.2/films/301/facts": "facts_301.json"})
+
+	facts, err := client.GetFacts(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(facts) != 2 {
+		t.Fatalf("Expected 2 facts, got %d", len(facts))
+	}
+	if facts[0].Type != "FACT" || facts[0].Spoiler {
+		t.Errorf("Unexpected fact: %+v", facts[0])
+	}
+	if facts[1].Type != "BLOOPER" || !facts[1].Spoiler {
+		t.Errorf("Unexpected blooper: %+v", facts[1])
+	}
+}
+
+func TestGetBoxOffice(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v2.2/films/301/box_office": "box_office_301.json"})
+
+	items, err := client.GetBoxOffice(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(items))
+	}
+	if items[0].Type != BoxOfficeBudget || items[0].Amount != 63000000 || items[0].CurrencyCode != "USD" {
+		t.Errorf("Unexpected budget: %+v", items[0])
+	}
+	if items[2].Type != BoxOfficeWorld || items[2].Amount != 463517383 {
+		t.Errorf("Unexpected world box office: %+v", items[2])
+	}
+}
+
+func TestGetVideos(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{"/api/v2.2/films/301/videos": "videos_301.json"})
+
+	videos, err := client.GetVideos(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(videos) != 4 {
+		t.Fatalf("Expected 4 videos, got %d", len(videos))
+	}
+	if id := videos[0].YouTubeID(); id != "vKQi3bBA1y8" {
+		t.Errorf("Expected YouTube ID vKQi3bBA1y8, got %q", id)
+	}
+	if id := videos[1].YouTubeID(); id != "m8e-FF8MsqU" {
+		t.Errorf("Expected YouTube ID from short link, got %q", id)
+	}
+	if videos[2].YouTubeID() != "" || videos[2].WidgetID() != "16871" {
+		t.Errorf("Unexpected widget video: %+v", videos[2])
+	}
+	if videos[3].YouTubeID() != "" || videos[3].WidgetID() != "" {
+		t.Errorf("Expected no IDs for %+v", videos[3])
+	}
+}
+
+func TestGetReviews(t *testing.T) {
+	client := newFixtureServer(t, map[string]string{
+		"/api/v2.2/films/301/reviews?page=1&order=USER_POSITIVE_RATING_DESC": "reviews_301.json",
+	})
+
+	page, err := client.GetReviews(context.Background(), 301, 1, ReviewsByHelpfulness)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+	r := page.Items[0]
+	if r.KinopoiskID != 2325456 || r.Type != ReviewPositive || r.PositiveRating != 153 || r.Author != "Morpheus" {
+		t.Errorf("Unexpected review: %+v", r)
+	}
+	if page.Items[1].Type != ReviewNeutral || page.Items[1].Description == "" {
+		t.Errorf("Unexpected review: %+v", page.Items[1])
+	}
+}
@@ -0,0 +1,89 @@
+package kinopoisk
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy — повторы запросов при сетевых ошибках, ответах 5xx и 429
+type RetryPolicy struct {
+	MaxAttempts int           // всего попыток, включая первую; 0 и 1 — без повторов
+	BaseDelay   time.Duration // пауза перед первым повтором, дальше удваивается
+	MaxDelay    time.Duration // потолок паузы; если API просит ждать дольше, повтора не будет
+}
+
+// DefaultRetryPolicy — три повтора с паузами около 0.5, 1 и 2 секунд
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// backoff возвращает паузу перед повтором номер attempt (с 1): экспонента,
+// половина которой случайна, чтобы параллельные запросы не повторялись хором
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// limiter — token bucket: в среднем rate запросов в секунду, до burst подряд
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait занимает токен и ждёт, пока он станет доступен. Токены резервируются
+// сразу, поэтому ожидающие запросы выстраиваются в очередь без гонок.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, time.Duration(deficit/l.rate*float64(time.Second))); err != nil {
+		l.mu.Lock()
+		l.tokens++ // запрос не состоится — возвращаем токен
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// sleep ждёт d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,176 @@
+package kinopoisk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient — клиент к тестовому серверу, который отвечает кодами из statuses по очереди
+func newTestClient(t *testing.T, opts Options, statuses ...int) (*Client, *int32) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		status := statuses[len(statuses)-1]
+		if int(n) <= len(statuses) {
+			status = statuses[n-1]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"kinopoiskId": 301, "nameRu": "Матрица"}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	c := NewClientWithOptions("test-api-key", opts)
+	c.baseURL = ts.URL
+	return c, &calls
+}
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func TestRetry_TemporaryErrors(t *testing.T) {
+	c, calls := newTestClient(t, Options{Retry: fastRetry}, 503, 429, 200)
+
+	f, err := c.GetFilm(context.Background(), 301)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.KinopoiskID != 301 || *calls != 3 {
+		t.Errorf("Expected film after 3 calls, got %+v after %d", f, *calls)
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	c, calls := newTestClient(t, Options{Retry: fastRetry}, 429)
+
+	_, err := c.GetFilm(context.Background(), 301)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", *calls)
+	}
+}
+
+func TestRetry_PermanentErrors(t *testing.T) {
+	for status, want := range map[int]error{
+		http.StatusNotFound:     ErrNotFound,
+		http.StatusUnauthorized: ErrUnauthorized,
+		http.StatusBadRequest:   nil,
+	} {
+		c, calls := newTestClient(t, Options{Retry: fastRetry}, status)
+		_, err := c.GetFilm(context.Background(), 301)
+		if err == nil || (want != nil && !errors.Is(err, want)) {
+			t.Errorf("status %d: expected %v, got %v", status, want, err)
+		}
+		if *calls != 1 {
+			t.Errorf("status %d: expected no retries, got %d calls", status, *calls)
+		}
+	}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	c, calls := newTestClient(t, Options{Retry: fastRetry}, http.StatusPaymentRequired, 200)
+
+	if _, err := c.GetFilm(context.Background(), 301); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	// до конца суток клиент больше не обращается к API
+	if _, err := c.GetFilm(context.Background(), 301); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, got %d", *calls)
+	}
+}
+
+func TestDailyQuota(t *testing.T) {
+	c, calls := newTestClient(t, Options{DailyQuota: 2}, 200)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetFilm(context.Background(), 301)
+		if i < 2 && err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if i == 2 && !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+		}
+	}
+	if *calls != 2 || c.RequestsToday() != 2 {
+		t.Errorf("Expected 2 requests, got %d calls, %d today", *calls, c.RequestsToday())
+	}
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	c, calls := newTestClient(t, Options{Retry: RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}}, 503)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.GetFilm(ctx, 301)
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != 503 {
+		t.Errorf("Expected the last API error, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, got %d", *calls)
+	}
+}
+
+func TestOpTimeout(t *testing.T) {
+	// повторы без конца, но вызов метода ограничен OpTimeout
+	c, _ := newTestClient(t, Options{Retry: RetryPolicy{MaxAttempts: 1000, BaseDelay: 5 * time.Millisecond}, OpTimeout: 30 * time.Millisecond}, 503)
+
+	start := time.Now()
+	_, err := c.GetFilm(context.Background(), 301)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the call to stop at OpTimeout, took %s", elapsed)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(100, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// два запроса сразу, ещё два — с интервалом 10 мс
+	if d := time.Since(start); d < 15*time.Millisecond {
+		t.Errorf("Expected limiter to wait, took %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newLimiter(1, 1).wait(ctx); err != nil {
+		t.Errorf("Expected the first token without waiting, got %v", err)
+	}
+	l = newLimiter(1, 1)
+	l.wait(context.Background())
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 300, 10: 300} {
+		max *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(attempt); d < max/2 || d > max {
+				t.Errorf("attempt %d: backoff %v outside [%v, %v]", attempt, d, max/2, max)
+			}
+		}
+	}
+}
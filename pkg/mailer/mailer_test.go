@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer_WritesEml(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFile(dir, "noreply@movies.local")
+
+	err := m.Send(Message{To: "user@example.com", Subject: "Подтвердите email", Body: "line1\nline2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"From: noreply@movies.local\r\n", "To: user@example.com\r\n", "line1\r\nline2"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in message:\n%s", want, data)
+		}
+	}
+	if len(m.Sent) != 1 || m.Sent[0].To != "user@example.com" {
+		t.Errorf("Unexpected sent list: %+v", m.Sent)
+	}
+}
+
+func TestFileMailer_LogOnly(t *testing.T) {
+	m := NewFile("", "noreply@movies.local")
+	if err := m.Send(Message{To: "a@b.c", Subject: "s", Body: "b"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(m.Sent) != 1 {
+		t.Errorf("Expected message to be recorded")
+	}
+}
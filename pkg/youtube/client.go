@@ -1,7 +1,9 @@
 package youtube
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,13 +14,40 @@ type Client struct {
 	httpClient *http.Client
 	apiKey     string
 	baseURL    string
+	opTimeout  time.Duration // дедлайн вызова метода целиком; 0 — без него
+	quota      quota
+	search     SearchOptions
+}
+
+// Options — таймауты клиента и параметры поиска обзоров
+type Options struct {
+	Timeout   time.Duration // таймаут одного HTTP-запроса
+	OpTimeout time.Duration // дедлайн вызова метода со всеми страницами
+	Search    SearchOptions
+}
+
+// DefaultOptions — настройки NewClient
+var DefaultOptions = Options{
+	Timeout:   10 * time.Second,
+	OpTimeout: 15 * time.Second,
+	Search: SearchOptions{
+		Language: "ru",
+		Region:   "RU",
+		Order:    "relevance",
+	},
 }
 
 func NewClient(apiKey string) *Client {
+	return NewClientWithOptions(apiKey, DefaultOptions)
+}
+
+func NewClientWithOptions(apiKey string, opts Options) *Client {
 	return &Client{
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		httpClient: &http.Client{Timeout: opts.Timeout},
 		apiKey:     apiKey,
 		baseURL:    "https://www.googleapis.com/youtube/v3",
+		opTimeout:  opts.OpTimeout,
+		search:     opts.Search,
 	}
 }
 
@@ -40,18 +69,38 @@ type searchResponse struct {
 	} `json:"items"`
 }
 
-// ReviewResult представляет один обзор из YouTube
-type ReviewResult struct {
-	VideoID      string `json:"video_id"`
-	VideoURL     string `json:"video_url"` // ссылка на видео
-	Title        string `json:"title"`
-	ChannelTitle string `json:"channel_title"`
-	ThumbnailURL string `json:"thumbnail_url"`
+// Виды видео о фильме (Video.Kind)
+const (
+	KindTrailer = "trailer"
+	KindTeaser  = "teaser"
+	KindReview  = "review"
+)
+
+// Video — ролик с YouTube. Вид, язык, дату, длительность и просмотры
+// заполняют только FindReviews и FindTrailers.
+type Video struct {
+	VideoID      string        `json:"video_id"`
+	VideoURL     string        `json:"video_url"` // ссылка на видео
+	Title        string        `json:"title"`
+	ChannelID    string        `json:"channel_id,omitempty"`
+	ChannelTitle string        `json:"channel_title"`
+	ThumbnailURL string        `json:"thumbnail_url"`
+	Kind         string        `json:"kind,omitempty"`
+	Language     string        `json:"language,omitempty"` // язык звука (ISO 639-1), если указан автором
+	PublishedAt  time.Time     `json:"published_at"`
+	Duration     time.Duration `json:"-"`
+	ViewCount    int64         `json:"view_count,omitempty"`
 }
 
-// SearchReviews ищет видеообзоры по названию фильма
-func (c *Client) SearchReviews(keyword string, maxResultsPerPage int) ([]ReviewResult, error) {
-	var result []ReviewResult
+// SearchReviews ищет видео по запросу «<keyword> обзор» без отбора;
+// обзоры для карточки фильма подбирает FindReviews
+func (c *Client) SearchReviews(ctx context.Context, keyword string, maxResultsPerPage int) ([]Video, error) {
+	if c.opTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opTimeout)
+		defer cancel()
+	}
+	var result []Video
 	pageToken := ""
 	count := 0
 
@@ -66,24 +115,14 @@ func (c *Client) SearchReviews(keyword string, maxResultsPerPage int) ([]ReviewR
 		}
 
 		u := fmt.Sprintf("%s/search?%s&key=%s", c.baseURL, params.Encode(), c.apiKey)
-		resp, err := c.httpClient.Get(u)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("youtube API status: %d", resp.StatusCode)
-		}
-
 		var sr searchResponse
-		if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		if err := c.get(ctx, u, &sr); err != nil {
 			return nil, err
 		}
 
 		for _, item := range sr.Items {
 			vid := item.ID.VideoID
-			result = append(result, ReviewResult{
+			result = append(result, Video{
 				VideoID:      vid,
 				VideoURL:     fmt.Sprintf("https://www.youtube.com/watch?v=%s", vid),
 				Title:        item.Snippet.Title,
@@ -102,3 +141,33 @@ func (c *Client) SearchReviews(keyword string, maxResultsPerPage int) ([]ReviewR
 
 	return result, nil
 }
+
+// get выполняет GET-запрос и декодирует ответ в out. После ответа об
+// исчерпанной квоте запросы до её восстановления не отправляются.
+func (c *Client) get(ctx context.Context, rawURL string, out interface{}) error {
+	if c.quota.exhausted(time.Now()) {
+		return ErrQuotaExceeded
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		se := &StatusError{StatusCode: resp.StatusCode}
+		var er errorResponse
+		if json.NewDecoder(resp.Body).Decode(&er) == nil && len(er.Error.Errors) > 0 {
+			se.Reason = er.Error.Errors[0].Reason
+		}
+		if errors.Is(se, ErrQuotaExceeded) {
+			c.quota.exhaust(time.Now())
+		}
+		return se
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
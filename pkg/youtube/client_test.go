@@ -1,7 +1,9 @@
 package youtube
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -97,7 +99,7 @@ func TestSearchReviews_Success(t *testing.T) {
 	}
 
 	// Вызываем тестируемый метод
-	results, err := client.SearchReviews("test", 5)
+	results, err := client.SearchReviews(context.Background(), "test", 5)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -107,7 +109,7 @@ func TestSearchReviews_Success(t *testing.T) {
 		t.Fatalf("Expected 5 result, got %d", len(results))
 	}
 
-	expected := ReviewResult{
+	expected := Video{
 		VideoID:      "video123",
 		VideoURL:     "https://www.youtube.com/watch?v=video123",
 		Title:        "Test Review",
@@ -162,7 +164,7 @@ func TestSearchReviews_ErrorHandling(t *testing.T) {
 				baseURL:    ts.URL,
 			}
 
-			_, err := client.SearchReviews("test", 5)
+			_, err := client.SearchReviews(context.Background(), "test", 5)
 			if err == nil {
 				t.Fatal("Expected error, got nil")
 			}
@@ -173,3 +175,69 @@ func TestSearchReviews_ErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchReviews_OpTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// отвечаем, только когда клиент сдастся
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	client := NewClientWithOptions("test-api-key", Options{Timeout: 10 * time.Second, OpTimeout: 20 * time.Millisecond})
+	client.baseURL = ts.URL
+
+	_, err := client.SearchReviews(context.Background(), "test", 5)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSearchReviews_QuotaExceeded(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "errors": [{"domain": "youtube.quota", "reason": "quotaExceeded"}]}}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = ts.URL
+
+	_, err := client.SearchReviews(context.Background(), "test", 5)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if !client.QuotaExhausted() {
+		t.Error("Expected the quota to be marked as exhausted")
+	}
+
+	// до восстановления квоты запросы не отправляются
+	if _, err := client.SearchReviews(context.Background(), "test", 5); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestQuota_ResetsAtPacificMidnight(t *testing.T) {
+	var q quota
+	now := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC) // 12:00 по тихоокеанскому
+	q.exhaust(now)
+	if !q.exhausted(now.Add(11 * time.Hour)) {
+		t.Error("Expected the quota to be exhausted before midnight")
+	}
+	if q.exhausted(now.Add(12 * time.Hour)) {
+		t.Error("Expected the quota to be restored at midnight")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	if err := (&StatusError{StatusCode: 403, Reason: "keyInvalid"}); !errors.Is(err, ErrForbidden) || errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+	if err := (&StatusError{StatusCode: 500}); errors.Is(err, ErrForbidden) || err.Error() != "youtube API status: 500" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
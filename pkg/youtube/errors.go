@@ -0,0 +1,81 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Типизированные ошибки API; проверяются через errors.Is
+var (
+	ErrQuotaExceeded = errors.New("youtube: daily quota exceeded")
+	ErrForbidden     = errors.New("youtube: invalid API key or access denied")
+)
+
+// StatusError — неуспешный ответ API. Reason — причина из тела ответа
+// (quotaExceeded, keyInvalid, …), по ней 403 различаются между собой.
+type StatusError struct {
+	StatusCode int
+	Reason     string
+}
+
+func (e *StatusError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("youtube API status: %d (%s)", e.StatusCode, e.Reason)
+	}
+	return fmt.Sprintf("youtube API status: %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.Reason == "quotaExceeded" || e.Reason == "dailyLimitExceeded":
+		return ErrQuotaExceeded
+	case e.StatusCode == http.StatusForbidden, e.StatusCode == http.StatusUnauthorized:
+		return ErrForbidden
+	}
+	return nil
+}
+
+// errorResponse — тело ошибки Google API
+type errorResponse struct {
+	Error struct {
+		Errors []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// quotaResetZone — часовой пояс, в полночь которого восстанавливается квота
+// (тихоокеанское время; без перехода на летнее время ждём на час дольше)
+var quotaResetZone = time.FixedZone("PST", -8*60*60)
+
+// quota помнит, что суточная квота ключа исчерпана, чтобы до её
+// восстановления не тратить время на заведомо неудачные запросы
+type quota struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// exhausted сообщает, исчерпана ли квота сейчас
+func (q *quota) exhausted(now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return now.Before(q.until)
+}
+
+// exhaust отмечает квоту исчерпанной до ближайшей полуночи
+func (q *quota) exhaust(now time.Time) {
+	t := now.In(quotaResetZone)
+	midnight := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, quotaResetZone)
+	q.mu.Lock()
+	q.until = midnight
+	q.mu.Unlock()
+}
+
+// QuotaExhausted сообщает, что квота ключа исчерпана и запросы до её
+// восстановления сразу завершаются ErrQuotaExceeded
+func (c *Client) QuotaExhausted() bool {
+	return c.quota.exhausted(time.Now())
+}
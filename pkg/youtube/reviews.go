@@ -0,0 +1,358 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// --- Relevant videos ---
+
+// SearchOptions — параметры поиска обзоров, общие для всех фильмов.
+// Пустые значения не передаются в API.
+type SearchOptions struct {
+	Language        string   // relevanceLanguage: язык обзоров, например ru
+	Region          string   // regionCode: страна, например RU
+	VideoDuration   string   // any, short (до 4 мин), medium (4–20 мин) или long
+	Order           string   // relevance, viewCount, date или rating
+	TrustedChannels []string // ID или названия каналов, чьи видео поднимаются выше
+}
+
+func (o SearchOptions) apply(params url.Values) {
+	for key, v := range map[string]string{
+		"relevanceLanguage": o.Language,
+		"regionCode":        o.Region,
+		"videoDuration":     o.VideoDuration,
+		"order":             o.Order,
+	} {
+		if v != "" {
+			params.Set(key, v)
+		}
+	}
+}
+
+// MovieQuery — фильм, видео о котором ищут FindReviews и FindTrailers
+type MovieQuery struct {
+	Title       string        // название, по которому ищем
+	OtherTitles []string      // оригинальное и английское названия
+	Year        int           // год выхода; 0 — неизвестен
+	Runtime     time.Duration // длительность фильма; 0 — неизвестна
+	MaxResults  int           // сколько видео вернуть; 0 — все подходящие
+}
+
+// candidatesPerSearch — сколько видео запрашивать для отбора: поиск стоит
+// 100 единиц квоты независимо от maxResults (до 50)
+const candidatesPerSearch = 25
+
+// minReviewScore — минимальная оценка видео, попадающего в выдачу
+const minReviewScore = 3
+
+// Начала слов в названии видео, по которым отличаем обзоры от трейлеров и
+// нарезок («обзор» находит и «обзора», «trailer» — и «trailers»)
+var (
+	reviewWords = []string{"обзор", "рецензия", "разбор", "мнение", "критика", "review", "breakdown", "explained"}
+	rejectWords = []string{"трейлер", "тизер", "trailer", "teaser", "клип", "нарезка", "edit", "fanmade", "amv", "fmv", "shorts", "tiktok", "смотреть онлайн", "full movie", "весь фильм"}
+)
+
+var yearPattern = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+
+type videoSearchResponse struct {
+	Items []struct {
+		ID struct {
+			VideoID string `json:"videoId"`
+		} `json:"id"`
+		Snippet struct {
+			Title        string `json:"title"`
+			ChannelID    string `json:"channelId"`
+			ChannelTitle string `json:"channelTitle"`
+			Thumbnails   struct {
+				High struct {
+					URL string `json:"url"`
+				} `json:"high"`
+			} `json:"thumbnails"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+type videosResponse struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			PublishedAt          time.Time `json:"publishedAt"`
+			DefaultAudioLanguage string    `json:"defaultAudioLanguage"`
+		} `json:"snippet"`
+		ContentDetails struct {
+			Duration string `json:"duration"` // ISO 8601, например PT12M30S
+		} `json:"contentDetails"`
+		Statistics struct {
+			ViewCount string `json:"viewCount"`
+		} `json:"statistics"`
+	} `json:"items"`
+}
+
+// FindReviews ищет обзоры фильма и отбирает релевантные: название видео должно
+// содержать название фильма, трейлеры, короткие ролики и загрузки фильма
+// целиком отбрасываются, а остальное ранжируется по словам «обзор», году,
+// просмотрам и доверенным каналам. Стоит 101 единицу квоты: поиск и запрос
+// длительности и просмотров найденных видео.
+func (c *Client) FindReviews(ctx context.Context, q MovieQuery) ([]Video, error) {
+	params := url.Values{}
+	params.Set("q", fmt.Sprintf("%s обзор", q.Title))
+	c.search.apply(params)
+	if q.Year > 0 {
+		// обзоры не выходят раньше фильма; год до выхода оставляем на ранние показы
+		params.Set("publishedAfter", time.Date(q.Year-1, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339))
+	}
+	return c.findVideos(ctx, q, params, func(v *Video) float64 {
+		v.Kind = KindReview
+		return ScoreReview(q, *v, c.search.TrustedChannels) - minReviewScore
+	})
+}
+
+// findVideos ищет видео по params, дополняет их длительностью, просмотрами и
+// языком и возвращает до q.MaxResults видео с неотрицательной оценкой score,
+// лучшие первыми
+func (c *Client) findVideos(ctx context.Context, q MovieQuery, params url.Values, score func(*Video) float64) ([]Video, error) {
+	if c.opTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opTimeout)
+		defer cancel()
+	}
+	params.Set("part", "snippet")
+	params.Set("type", "video")
+	params.Set("maxResults", strconv.Itoa(candidatesPerSearch))
+	var sr videoSearchResponse
+	if err := c.get(ctx, fmt.Sprintf("%s/search?%s&key=%s", c.baseURL, params.Encode(), c.apiKey), &sr); err != nil {
+		return nil, err
+	}
+	if len(sr.Items) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(sr.Items))
+	for _, item := range sr.Items {
+		ids = append(ids, item.ID.VideoID)
+	}
+	details, err := c.videoDetails(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		Video
+		score float64
+	}
+	var found []scored
+	seen := make(map[string]bool, len(sr.Items))
+	for _, item := range sr.Items {
+		vid := item.ID.VideoID
+		d, ok := details[vid]
+		if !ok || seen[vid] {
+			continue // удалено, скрыто или повтор
+		}
+		seen[vid] = true
+		v := Video{
+			VideoID:      vid,
+			VideoURL:     fmt.Sprintf("https://www.youtube.com/watch?v=%s", vid),
+			Title:        item.Snippet.Title,
+			ChannelID:    item.Snippet.ChannelID,
+			ChannelTitle: item.Snippet.ChannelTitle,
+			ThumbnailURL: item.Snippet.Thumbnails.High.URL,
+			Language:     d.Language,
+			PublishedAt:  d.PublishedAt,
+			Duration:     d.Duration,
+			ViewCount:    d.ViewCount,
+		}
+		if s := score(&v); s >= 0 {
+			found = append(found, scored{v, s})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+
+	if q.MaxResults > 0 && len(found) > q.MaxResults {
+		found = found[:q.MaxResults]
+	}
+	result := make([]Video, len(found))
+	for i, f := range found {
+		result[i] = f.Video
+	}
+	return result, nil
+}
+
+type videoDetail struct {
+	Language    string
+	PublishedAt time.Time
+	Duration    time.Duration
+	ViewCount   int64
+}
+
+// videoDetails получает язык, дату публикации, длительность и число просмотров
+// видео (1 единица квоты)
+func (c *Client) videoDetails(ctx context.Context, ids []string) (map[string]videoDetail, error) {
+	params := url.Values{}
+	params.Set("part", "snippet,contentDetails,statistics")
+	params.Set("id", strings.Join(ids, ","))
+	var vr videosResponse
+	if err := c.get(ctx, fmt.Sprintf("%s/videos?%s&key=%s", c.baseURL, params.Encode(), c.apiKey), &vr); err != nil {
+		return nil, err
+	}
+	details := make(map[string]videoDetail, len(vr.Items))
+	for _, v := range vr.Items {
+		views, _ := strconv.ParseInt(v.Statistics.ViewCount, 10, 64) // скрытая статистика — 0
+		lang, _, _ := strings.Cut(strings.ToLower(v.Snippet.DefaultAudioLanguage), "-")
+		details[v.ID] = videoDetail{
+			Language:    lang,
+			PublishedAt: v.Snippet.PublishedAt,
+			Duration:    parseDuration(v.ContentDetails.Duration),
+			ViewCount:   views,
+		}
+	}
+	return details, nil
+}
+
+// ScoreReview оценивает, насколько видео похоже на обзор фильма из q.
+// Отрицательная оценка — видео точно не подходит.
+func ScoreReview(q MovieQuery, r Video, trustedChannels []string) float64 {
+	title := normalize(r.Title)
+	if !matchesTitle(title, q) {
+		return -1
+	}
+	if hasAnyWord(title, rejectWords) {
+		return -1
+	}
+	if r.Duration < 3*time.Minute {
+		return -1 // shorts, нарезки и тизеры
+	}
+	if q.Runtime > 0 && r.Duration >= q.Runtime*3/4 {
+		return -1 // фильм целиком
+	}
+
+	var score float64
+	if hasAnyWord(title, reviewWords) {
+		score += 3
+	}
+	if containsAny(title, q.OtherTitles) {
+		score++
+	}
+	score += yearScore(title, q)
+	if r.Duration >= 5*time.Minute {
+		score++
+	}
+	score += popularity(r)
+	if isTrusted(r, trustedChannels) {
+		score += 5
+	}
+	return score
+}
+
+// matchesTitle сообщает, что в нормализованном названии видео есть одно из
+// названий фильма
+func matchesTitle(title string, q MovieQuery) bool {
+	return containsPhrase(title, q.Title) || containsAny(title, q.OtherTitles)
+}
+
+// yearScore поднимает видео с годом выхода фильма в названии и опускает
+// видео про одноимённые фильмы других лет
+func yearScore(title string, q MovieQuery) float64 {
+	if q.Year == 0 {
+		return 0
+	}
+	var score float64
+	for _, y := range yearPattern.FindAllString(title, -1) {
+		if containsPhrase(normalize(q.Title), y) {
+			continue // год — часть названия («1917»)
+		}
+		if y == strconv.Itoa(q.Year) {
+			score++
+		} else {
+			score -= 2
+		}
+	}
+	return score
+}
+
+// popularity — вклад просмотров в оценку: миллион просмотров — +3
+func popularity(v Video) float64 {
+	return math.Log10(float64(v.ViewCount)+1) / 2
+}
+
+// isTrusted сообщает, что канал видео есть в списке (по ID или названию)
+func isTrusted(r Video, trustedChannels []string) bool {
+	for _, ch := range trustedChannels {
+		if ch == r.ChannelID || strings.EqualFold(ch, r.ChannelTitle) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize приводит текст к виду для сравнения: нижний регистр, ё → е,
+// знаки препинания заменены пробелами, пробелы схлопнуты
+func normalize(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// containsPhrase ищет фразу в нормализованном тексте целыми словами,
+// чтобы короткое название («Оно») не находилось внутри других слов
+func containsPhrase(text, phrase string) bool {
+	phrase = normalize(phrase)
+	if phrase == "" {
+		return false
+	}
+	return strings.Contains(" "+text+" ", " "+phrase+" ")
+}
+
+// hasWord ищет в нормализованном тексте слово, начинающееся с prefix
+func hasWord(text, prefix string) bool {
+	return strings.Contains(" "+text, " "+normalize(prefix))
+}
+
+func hasAnyWord(text string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if hasWord(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(text string, titles []string) bool {
+	for _, t := range titles {
+		if containsPhrase(text, t) {
+			return true
+		}
+	}
+	return false
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration разбирает длительность ISO 8601 из API (PT1H2M3S);
+// нераспознанная строка — 0
+func parseDuration(s string) time.Duration {
+	m := isoDuration.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if n, err := strconv.Atoi(m[i+1]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	return d
+}
@@ -0,0 +1,136 @@
+package youtube
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindReviews(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/search":
+			if q.Get("q") != "Матрица обзор" {
+				t.Errorf("Expected query 'Матрица обзор', got '%s'", q.Get("q"))
+			}
+			if q.Get("relevanceLanguage") != "ru" || q.Get("regionCode") != "RU" || q.Get("order") != "viewCount" {
+				t.Errorf("Search options not passed: %s", r.URL.RawQuery)
+			}
+			if q.Get("videoDuration") != "" {
+				t.Errorf("Expected no videoDuration, got %s", q.Get("videoDuration"))
+			}
+			if q.Get("publishedAfter") != "1998-01-01T00:00:00Z" {
+				t.Errorf("Unexpected publishedAfter: %s", q.Get("publishedAfter"))
+			}
+			w.Write([]byte(`{"items": [
+				{"id": {"videoId": "fan"}, "snippet": {"title": "Матрица | Fan Edit", "channelTitle": "Fan"}},
+				{"id": {"videoId": "pop"}, "snippet": {"title": "Матрица — обзор фильма", "channelTitle": "Блогер"}},
+				{"id": {"videoId": "gone"}, "snippet": {"title": "Матрица обзор", "channelTitle": "Удалено"}},
+				{"id": {"videoId": "trusted"}, "snippet": {"title": "Разбор «Матрицы»: Матрица (1999)", "channelId": "UC123", "channelTitle": "Киноканал"}},
+				{"id": {"videoId": "other"}, "snippet": {"title": "Матрица: Воскрешение 2021 обзор", "channelTitle": "Блогер"}}
+			]}`))
+		case "/videos":
+			if q.Get("id") != "fan,pop,gone,trusted,other" {
+				t.Errorf("Unexpected video ids: %s", q.Get("id"))
+			}
+			if q.Get("part") != "snippet,contentDetails,statistics" {
+				t.Errorf("Unexpected part: %s", q.Get("part"))
+			}
+			w.Write([]byte(`{"items": [
+				{"id": "fan", "contentDetails": {"duration": "PT5M"}, "statistics": {"viewCount": "5000000"}},
+				{"id": "pop", "snippet": {"publishedAt": "2019-03-31T10:00:00Z", "defaultAudioLanguage": "ru-RU"}, "contentDetails": {"duration": "PT12M30S"}, "statistics": {"viewCount": "100000"}},
+				{"id": "trusted", "contentDetails": {"duration": "PT25M"}, "statistics": {"viewCount": "1000"}},
+				{"id": "other", "contentDetails": {"duration": "PT15M"}, "statistics": {"viewCount": "10"}}
+			]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClientWithOptions("test-api-key", Options{
+		Timeout: 10 * time.Second,
+		Search:  SearchOptions{Language: "ru", Region: "RU", Order: "viewCount", TrustedChannels: []string{"UC123"}},
+	})
+	client.baseURL = ts.URL
+
+	results, err := client.FindReviews(context.Background(), MovieQuery{
+		Title:       "Матрица",
+		OtherTitles: []string{"The Matrix"},
+		Year:        1999,
+		Runtime:     136 * time.Minute,
+		MaxResults:  5,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.VideoID)
+	}
+	if len(ids) != 2 || ids[0] != "trusted" || ids[1] != "pop" {
+		t.Fatalf("Expected [trusted pop], got %v", ids)
+	}
+	pop := results[1]
+	if pop.Duration != 12*time.Minute+30*time.Second || pop.ViewCount != 100000 {
+		t.Errorf("Unexpected details: %+v", pop)
+	}
+	if pop.Kind != KindReview || pop.Language != "ru" || !pop.PublishedAt.Equal(time.Date(2019, 3, 31, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected kind, language or date: %+v", pop)
+	}
+}
+
+func TestScoreReview(t *testing.T) {
+	q := MovieQuery{Title: "Оно", OtherTitles: []string{"It"}, Year: 2017, Runtime: 135 * time.Minute}
+	review := func(title string, d time.Duration) Video {
+		return Video{Title: title, Duration: d, ChannelTitle: "Канал"}
+	}
+
+	tests := []struct {
+		name   string
+		r      Video
+		accept bool
+	}{
+		{"review", review("ОНО — обзор фильма", 10*time.Minute), true},
+		{"english title", review("It (2017) Movie Review", 8*time.Minute), true},
+		{"title inside word", review("Ономатопея: обзор", 10*time.Minute), false},
+		{"trailer", review("Оно — официальный трейлер", 10*time.Minute), false},
+		{"shorts", review("Оно обзор #shorts", 10*time.Minute), false},
+		{"too short", review("Оно обзор", time.Minute), false},
+		{"full movie", review("Оно обзор", 130*time.Minute), false},
+		{"other year", review("Оно 1990 обзор", 10*time.Minute), false},
+	}
+	for _, tt := range tests {
+		if got := ScoreReview(q, tt.r, nil) >= minReviewScore; got != tt.accept {
+			t.Errorf("%s: expected accept=%v, score %.2f", tt.name, tt.accept, ScoreReview(q, tt.r, nil))
+		}
+	}
+
+	plain := review("Оно", 10*time.Minute)
+	if ScoreReview(q, plain, nil) >= minReviewScore {
+		t.Error("Expected a video without review words to be rejected")
+	}
+	if ScoreReview(q, plain, []string{"канал"}) < minReviewScore {
+		t.Error("Expected a trusted channel to be accepted")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT45S":     45 * time.Second,
+		"PT12M30S":  12*time.Minute + 30*time.Second,
+		"PT1H2M":    time.Hour + 2*time.Minute,
+		"P1DT1H":    25 * time.Hour,
+		"P0D":       0,
+		"bad input": 0,
+	}
+	for in, want := range tests {
+		if got := parseDuration(in); got != want {
+			t.Errorf("parseDuration(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// --- Trailers ---
+
+// minTrailerScore — минимальная оценка трейлера, попадающего в выдачу
+const minTrailerScore = 1
+
+var (
+	trailerWords       = []string{"трейлер", "trailer", "тизер", "teaser"}
+	teaserWords        = []string{"тизер", "teaser"}
+	officialWords      = []string{"официальный", "official"}
+	trailerRejectWords = []string{"обзор", "разбор", "реакция", "reaction", "review", "concept", "концепт", "fanmade", "фанатский", "пародия", "parody", "edit"}
+)
+
+// FindTrailers ищет трейлеры и тизеры фильма: название видео должно содержать
+// название фильма и слово «трейлер» или «тизер», а обзоры, реакции и
+// фанатские концепты отбрасываются. Официальные трейлеры, совпадение года,
+// просмотры и доверенные каналы поднимают видео выше. Стоит 101 единицу квоты.
+func (c *Client) FindTrailers(ctx context.Context, q MovieQuery) ([]Video, error) {
+	params := url.Values{}
+	params.Set("q", fmt.Sprintf("%s трейлер", q.Title))
+	c.search.apply(params)
+	params.Set("videoDuration", "short") // трейлеры короче 4 минут, настройка обзоров не подходит
+	if q.Year > 0 {
+		// трейлеры выходят за год-два до премьеры
+		params.Set("publishedAfter", time.Date(q.Year-2, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339))
+	}
+	return c.findVideos(ctx, q, params, func(v *Video) float64 {
+		v.Kind = TrailerKind(v.Title)
+		return ScoreTrailer(q, *v, c.search.TrustedChannels) - minTrailerScore
+	})
+}
+
+// TrailerKind относит ролик к тизерам или трейлерам по названию
+func TrailerKind(title string) string {
+	if hasAnyWord(normalize(title), teaserWords) {
+		return KindTeaser
+	}
+	return KindTrailer
+}
+
+// ScoreTrailer оценивает, насколько видео похоже на трейлер фильма из q.
+// Отрицательная оценка — видео точно не подходит.
+func ScoreTrailer(q MovieQuery, v Video, trustedChannels []string) float64 {
+	title := normalize(v.Title)
+	if !matchesTitle(title, q) || !hasAnyWord(title, trailerWords) || hasAnyWord(title, trailerRejectWords) {
+		return -1
+	}
+	if v.Duration < 20*time.Second || v.Duration > 6*time.Minute {
+		return -1
+	}
+
+	score := yearScore(title, q) + popularity(v)
+	if hasAnyWord(title, officialWords) {
+		score += 2
+	}
+	if isTrusted(v, trustedChannels) {
+		score += 5
+	}
+	return score
+}
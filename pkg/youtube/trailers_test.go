@@ -0,0 +1,86 @@
+package youtube
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindTrailers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/search":
+			if q.Get("q") != "Дюна трейлер" {
+				t.Errorf("Expected query 'Дюна трейлер', got '%s'", q.Get("q"))
+			}
+			if q.Get("videoDuration") != "short" {
+				t.Errorf("Expected videoDuration short, got %s", q.Get("videoDuration"))
+			}
+			if q.Get("publishedAfter") != "2019-01-01T00:00:00Z" {
+				t.Errorf("Unexpected publishedAfter: %s", q.Get("publishedAfter"))
+			}
+			w.Write([]byte(`{"items": [
+				{"id": {"videoId": "teaser"}, "snippet": {"title": "Дюна — тизер", "channelTitle": "Студия"}},
+				{"id": {"videoId": "concept"}, "snippet": {"title": "Дюна 2 — Concept Trailer", "channelTitle": "Fan"}},
+				{"id": {"videoId": "official"}, "snippet": {"title": "Дюна — Официальный трейлер (2021)", "channelTitle": "Студия"}},
+				{"id": {"videoId": "reaction"}, "snippet": {"title": "Реакция на трейлер Дюны", "channelTitle": "Блогер"}}
+			]}`))
+		case "/videos":
+			w.Write([]byte(`{"items": [
+				{"id": "teaser", "contentDetails": {"duration": "PT1M5S"}, "statistics": {"viewCount": "50000"}},
+				{"id": "concept", "contentDetails": {"duration": "PT2M"}, "statistics": {"viewCount": "9000000"}},
+				{"id": "official", "snippet": {"defaultAudioLanguage": "ru"}, "contentDetails": {"duration": "PT2M40S"}, "statistics": {"viewCount": "2000000"}},
+				{"id": "reaction", "contentDetails": {"duration": "PT3M"}, "statistics": {"viewCount": "1000"}}
+			]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClientWithOptions("test-api-key", Options{
+		Timeout: 10 * time.Second,
+		Search:  SearchOptions{VideoDuration: "long"},
+	})
+	client.baseURL = ts.URL
+
+	results, err := client.FindTrailers(context.Background(), MovieQuery{Title: "Дюна", OtherTitles: []string{"Dune"}, Year: 2021})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].VideoID != "official" || results[1].VideoID != "teaser" {
+		t.Fatalf("Expected [official teaser], got %+v", results)
+	}
+	if results[0].Kind != KindTrailer || results[0].Language != "ru" || results[1].Kind != KindTeaser {
+		t.Errorf("Unexpected kinds: %+v", results)
+	}
+}
+
+func TestScoreTrailer(t *testing.T) {
+	q := MovieQuery{Title: "Оно", Year: 2017}
+	trailer := func(title string, d time.Duration) Video {
+		return Video{Title: title, Duration: d, ViewCount: 1000}
+	}
+
+	tests := []struct {
+		name   string
+		v      Video
+		accept bool
+	}{
+		{"trailer", trailer("Оно — трейлер", 2*time.Minute), true},
+		{"teaser", trailer("ОНО (2017) | Тизер", time.Minute), true},
+		{"no trailer word", trailer("Оно — сцена", 2*time.Minute), false},
+		{"review", trailer("Оно — обзор трейлера", 2*time.Minute), false},
+		{"too long", trailer("Оно — трейлер", 10*time.Minute), false},
+		{"other year", trailer("Оно 1990 трейлер", 2*time.Minute), false},
+	}
+	for _, tt := range tests {
+		if got := ScoreTrailer(q, tt.v, nil) >= minTrailerScore; got != tt.accept {
+			t.Errorf("%s: expected accept=%v, score %.2f", tt.name, tt.accept, ScoreTrailer(q, tt.v, nil))
+		}
+	}
+}
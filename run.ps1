@@ -31,8 +31,8 @@ docker-compose run --rm builder pwsh -Command "go test ./... -cover"
 Write-Host "→ Running integration tests…"
 docker-compose run --rm builder pwsh -Command "go test ./internal/handlers -timeout ${IntegrationTimeout}s -cover"
 
-# 6) Запустить API (в нём при старте выполнится import_all_movies)
-Write-Host "→ Starting API with import_all_movies…"
-docker-compose up -d api
+# 6) Запустить API и догрузить каталог (импорт продолжает с чекпоинта)
+Write-Host "→ Starting API and importer…"
+docker-compose up -d api importer
 
 Write-Host "✅ All done! Service running on port $($env:PORT)"
@@ -25,8 +25,8 @@ echo "→ Running integration tests…"
 # здесь жёсткий timeout, если нужно:
 docker-compose run --rm builder go test ./internal/handlers -timeout 120s -cover
 
-# 6) Поднять API (оно само импортит фильмы перед стартом)
-echo "→ Starting API with import_all_movies"
-docker-compose up -d api
+# 6) Поднять API и догрузить каталог (импорт продолжает с чекпоинта)
+echo "→ Starting API and importer"
+docker-compose up -d api importer
 
 echo "✅ All done! Visit: http://localhost:${PORT:-8080}"
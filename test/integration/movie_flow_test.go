@@ -16,12 +16,15 @@ func TestMovieEndpoints(t *testing.T) {
 	defer resp.Body.Close()
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var movies []struct {
-		MovieID int     `json:"movie_id"`
-		Title   string  `json:"title"`
-		Rating  float64 `json:"ratingKinopoisk"`
+	var list struct {
+		Items []struct {
+			MovieID int     `json:"movie_id"`
+			Title   string  `json:"title"`
+			Rating  float64 `json:"ratingKinopoisk"`
+		} `json:"items"`
 	}
-	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&movies))
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	movies := list.Items
 	assert.LessOrEqual(t, len(movies), 5)
 
 	// GetMovieByID
@@ -17,14 +17,16 @@ func getFirstMovieID(t *testing.T) int {
 	defer resp.Body.Close()
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var arr []struct {
-		MovieID int `json:"movie_id"`
+	var list struct {
+		Items []struct {
+			MovieID int `json:"movie_id"`
+		} `json:"items"`
 	}
-	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&arr))
-	if len(arr) == 0 {
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	if len(list.Items) == 0 {
 		t.Fatal("no movies available to test with")
 	}
-	return arr[0].MovieID
+	return list.Items[0].MovieID
 }
 
 func TestRatingsFlow(t *testing.T) {
@@ -28,7 +28,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// 3) Регистрируем нового пользователя
-	regBody, _ := json.Marshal(map[string]string{"email": "int@int.com", "password": "pass123"})
+	regBody, _ := json.Marshal(map[string]string{"email": "int@int.com", "password": "pass1234"})
 	resp, err := http.Post(baseURL+"/auth/register", "application/json", bytes.NewReader(regBody))
 	if err != nil {
 		panic("register error: " + err.Error())